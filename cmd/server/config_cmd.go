@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"api/internal/config"
+)
+
+// Exit codes of the config subcommand.
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitUsage   = 2
+)
+
+// configUsage describes the config subcommand.
+const configUsage = `Usage: server config <command> [flags]
+
+Commands:
+  print      print the effective configuration (secrets redacted)
+  validate   check the configuration and exit non-zero if it is invalid
+
+Both commands accept the same flags as the server; run
+"server config print --help" for the full list.
+`
+
+// runConfigCommand runs "server config print" or "server config validate"
+// with args following "config". It loads and validates the configuration
+// without starting the server and returns the process exit code.
+func runConfigCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, configUsage)
+		return exitUsage
+	}
+
+	command, args := args[0], args[1:]
+	fs := flag.NewFlagSet("server config "+command, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var format *string
+	switch command {
+	case "print":
+		format = fs.String("format", config.FormatEnv, "output format (env, yaml, json)")
+	case "validate":
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, configUsage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "unknown config command %q\n\n%s", command, configUsage)
+		return exitUsage
+	}
+
+	cfg, err := config.LoadFlagSet(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitInvalid
+	}
+
+	if command == "validate" {
+		if cfg.File() != "" {
+			fmt.Fprintf(stdout, "configuration is valid (config file %s)\n", cfg.File())
+		} else {
+			fmt.Fprintln(stdout, "configuration is valid")
+		}
+		return exitOK
+	}
+
+	out, err := cfg.Render(*format)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	_, _ = stdout.Write(out)
+	return exitOK
+}
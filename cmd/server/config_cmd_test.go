@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunConfigCommand(t *testing.T) {
+	dataFile := filepath.Join(t.TempDir(), "data.json")
+	if err := os.WriteFile(dataFile, []byte("[]"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	t.Setenv("DATA_FILE_PATH", dataFile)
+	t.Setenv("ADMIN_TOKEN", "s3cr3t")
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{name: "no command", args: nil, wantCode: exitUsage, wantStderr: "Usage: server config"},
+		{name: "unknown command", args: []string{"dump"}, wantCode: exitUsage, wantStderr: `unknown config command "dump"`},
+		{name: "help", args: []string{"help"}, wantCode: exitOK, wantStdout: "Usage: server config"},
+		{name: "print env", args: []string{"print"}, wantCode: exitOK, wantStdout: "ADMIN_TOKEN=[REDACTED]"},
+		{name: "print yaml with flags", args: []string{"print", "--format", "yaml", "--port", "4000"}, wantCode: exitOK, wantStdout: `port: "4000"`},
+		{name: "print json", args: []string{"print", "--format=json"}, wantCode: exitOK, wantStdout: `"admin_token": "[REDACTED]"`},
+		{name: "print unknown format", args: []string{"print", "--format", "xml"}, wantCode: exitUsage, wantStderr: "unsupported format"},
+		{name: "validate", args: []string{"validate"}, wantCode: exitOK, wantStdout: "configuration is valid"},
+		{name: "validate invalid", args: []string{"validate", "--port", "0"}, wantCode: exitInvalid, wantStderr: "invalid port: 0"},
+		{name: "validate rejects format flag", args: []string{"validate", "--format", "json"}, wantCode: exitInvalid, wantStderr: "flag provided but not defined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := runConfigCommand(tt.args, &stdout, &stderr)
+
+			assert.Equal(t, tt.wantCode, code, "stderr: %s", stderr.String())
+			assert.Contains(t, stdout.String(), tt.wantStdout)
+			assert.Contains(t, stderr.String(), tt.wantStderr)
+			assert.NotContains(t, stdout.String()+stderr.String(), "s3cr3t")
+		})
+	}
+}
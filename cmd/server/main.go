@@ -0,0 +1,329 @@
+// Package main is the entry point for the API server.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"api/internal/config"
+	"api/internal/handler"
+	"api/internal/middleware"
+	"api/internal/server"
+	"api/internal/service"
+	"api/internal/version"
+	"api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// shutdownTimeout bounds how long in-flight requests may take to finish
+// after a termination signal is received.
+const shutdownTimeout = 30 * time.Second
+
+// upgradeTimeout bounds how long a new process started on SIGUSR2 may take
+// to start serving before the upgrade is abandoned.
+const upgradeTimeout = 30 * time.Second
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfigCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "server error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run loads configuration, starts the HTTP server and blocks until it is shut down.
+func run() error {
+	cfg, err := config.LoadArgs(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := logger.Setup(cfg.LoggerOptions()); err != nil {
+		return err
+	}
+	defer logger.Close()
+
+	info := version.Get()
+	logger.Info("Starting server",
+		"version", info.Version,
+		"revision", info.Revision,
+		"dirty", info.Dirty,
+		"go_version", info.GoVersion,
+		"config", cfg,
+	)
+
+	svc, err := service.NewService(cfg.DataFilePath)
+	if err != nil {
+		return err
+	}
+
+	reloader := config.NewReloader(cfg)
+	h := handler.NewHandler(svc, handler.WithConfigFunc(reloader.Current))
+
+	accessLog, closeAccessLog, err := openAccessLog(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeAccessLog()
+
+	origins := middleware.NewAllowedOrigins(cfg.CORSAllowedOrigins)
+
+	var certs *server.CertReloader
+	if cfg.TLSEnabled() {
+		if certs, err = server.NewCertReloader(cfg.TLSOptions()); err != nil {
+			return err
+		}
+	}
+
+	// Apply reloadable settings live; a reload also reloads the TLS
+	// certificates and the data file. Hooks that can fail come first, since
+	// a failure rolls back those already run.
+	if certs != nil {
+		reloader.OnReload(func(_, _ *config.Config) error { return certs.Reload() })
+	}
+	reloader.OnReload(func(_, next *config.Config) error {
+		return logger.Reconfigure(next.LoggerOptions())
+	})
+	reloader.OnReload(func(_, next *config.Config) error {
+		origins.Set(next.CORSAllowedOrigins)
+		return nil
+	})
+	// The data file is not part of the configuration, so a broken one
+	// keeps the current data without failing the reload.
+	reloader.OnReload(func(_, _ *config.Config) error {
+		if err := svc.Reload(); err != nil {
+			logger.Component(context.Background(), "service").Error("Data reload failed; keeping current data", "error", err)
+		}
+		return nil
+	})
+
+	if cfg.LogLevel != "debug" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	listeners, handoff, err := openListeners(cfg.ListenerSpecs())
+	if err != nil {
+		return err
+	}
+
+	notifier := server.NewNotifier()
+	reloader.SetNotifier(notifier)
+
+	opts := server.Options{
+		Handler: setupRouter(h, routerConfig{
+			accessLog:  accessLog,
+			origins:    origins,
+			adminToken: func() string { return reloader.Current().AdminToken },
+		}),
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+	}
+	if certs != nil {
+		opts.TLSConfig = certs.TLSConfig()
+	}
+	srv := server.New(opts, listeners)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	watchLogLevelSignals(ctx)
+	reloader.Watch(ctx)
+	if certs != nil {
+		go certs.Watch(ctx, cfg.ConfigWatchInterval)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		for _, l := range listeners {
+			logger.Info("Server listening",
+				"listener", l.Spec.String(),
+				"addr", l.Addr().String(),
+				"tls", certs != nil && l.Spec.SupportsTLS(),
+			)
+		}
+		errCh <- srv.Serve()
+	}()
+
+	// The data is loaded and every listener is open, so connections are
+	// accepted from here on. After an upgrade, systemd must know this
+	// process as the main one before the previous process stops its
+	// watchdog pings, so the service manager is told first.
+	if err := notifier.Ready(); err != nil {
+		logger.Warn("Could not notify service manager", "error", err)
+	}
+	if handoff != nil {
+		if err := handoff.Ready(); err != nil {
+			logger.Warn("Could not notify previous process", "error", err)
+		}
+	}
+	watchdogCtx, stopWatchdog := context.WithCancel(context.Background())
+	defer stopWatchdog()
+	go notifier.Watchdog(watchdogCtx)
+
+	upgradeCh, stopUpgrade := notifyUpgrade()
+	defer stopUpgrade()
+
+	drainTimeout := shutdownTimeout
+	stopping := false
+	for !stopping {
+		select {
+		case err := <-errCh:
+			if err != nil {
+				return fmt.Errorf("server failed: %w", err)
+			}
+			return nil
+		case <-ctx.Done():
+			stopping = true
+			logger.Info("Shutting down server")
+			if err := notifier.Stopping(); err != nil {
+				logger.Warn("Could not notify service manager", "error", err)
+			}
+		case <-upgradeCh:
+			logger.Info("Upgrade requested; starting new process")
+			proc, err := server.Upgrade(listeners, upgradeTimeout)
+			if err != nil {
+				logger.Error("Upgrade failed; continuing to serve", "error", err)
+				continue
+			}
+
+			// The new process now owns the sockets and reports to the
+			// service manager, so this one only drains.
+			stopping = true
+			stopWatchdog()
+			if cfg.WriteTimeout > 0 {
+				drainTimeout = cfg.WriteTimeout
+			}
+			logger.Info("New process is serving; draining in-flight requests", "pid", proc.Pid, "timeout", drainTimeout)
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("server shutdown failed: %w", err)
+	}
+
+	logger.Info("Server stopped")
+	return nil
+}
+
+// openListeners returns the sockets handed over by a previous process
+// during an upgrade or passed by systemd socket activation, if any, and
+// otherwise opens a listener for each spec. If any fails, those already
+// opened are closed. The handoff is non-nil only after an upgrade.
+func openListeners(specs []server.ListenerSpec) ([]server.Listener, *server.Handoff, error) {
+	handoff, err := server.InheritHandoff()
+	if err != nil {
+		return nil, nil, err
+	}
+	if handoff != nil {
+		logger.Info("Using sockets from previous process", "count", len(handoff.Listeners))
+		return handoff.Listeners, handoff, nil
+	}
+
+	inherited, err := server.SystemdListeners(specs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(inherited) > 0 {
+		logger.Info("Using sockets from systemd", "count", len(inherited))
+		return inherited, nil, nil
+	}
+
+	listeners := make([]server.Listener, 0, len(specs))
+	for _, spec := range specs {
+		ln, err := server.Listen(spec)
+		if err != nil {
+			for _, l := range listeners {
+				_ = l.Close()
+			}
+			return nil, nil, err
+		}
+		listeners = append(listeners, server.Listener{Listener: ln, Spec: spec})
+	}
+	return listeners, nil, nil
+}
+
+// openAccessLog opens the access log sink for the configured format and
+// returns a function that closes it. The slog format logs through the
+// global logger and needs no sink.
+func openAccessLog(cfg *config.Config) (middleware.LoggerConfig, func(), error) {
+	accessLog := middleware.LoggerConfig{Format: cfg.AccessLogFormat}
+	if cfg.AccessLogFormat == "" || cfg.AccessLogFormat == middleware.AccessLogSlog {
+		return accessLog, func() {}, nil
+	}
+
+	out, err := logger.OpenSink(cfg.AccessLogOutput, cfg.LoggerOptions().File)
+	if err != nil {
+		return accessLog, nil, fmt.Errorf("could not open access log: %w", err)
+	}
+	accessLog.Output = out
+
+	return accessLog, func() { _ = out.Close() }, nil
+}
+
+// routerConfig holds the middleware settings used by setupRouter.
+type routerConfig struct {
+	// accessLog configures request logging.
+	accessLog middleware.LoggerConfig
+	// origins are the CORS origins; nil allows any origin.
+	origins *middleware.AllowedOrigins
+	// adminToken returns the token required by the admin endpoints; nil
+	// or an empty token disables them.
+	adminToken func() string
+}
+
+// setupRouter creates the gin engine with middleware and routes.
+func setupRouter(h *handler.Handler, rc routerConfig) *gin.Engine {
+	cors := middleware.CORS()
+	if rc.origins != nil {
+		cors = middleware.CORSWithOrigins(rc.origins)
+	}
+
+	router := gin.New()
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(middleware.NoRoute())
+	router.NoMethod(middleware.NoMethod(router.Routes))
+	router.Use(
+		middleware.Recovery(),
+		middleware.RequestID(),
+		middleware.LoggerWithConfig(rc.accessLog),
+		cors,
+	)
+
+	// Requests are validated per group, so that admin requests are
+	// authenticated before their bodies are looked at.
+	validate := middleware.ValidateRequests(handler.Spec())
+	public := router.Group("", validate)
+
+	public.GET("/health", h.HealthCheck)
+	public.GET("/version", h.Version)
+	public.GET("/openapi.json", h.OpenAPI)
+
+	adminToken := rc.adminToken
+	if adminToken == nil {
+		adminToken = func() string { return "" }
+	}
+	admin := router.Group("/admin", middleware.AdminAuth(adminToken), validate)
+	admin.GET("/log-level", h.GetLogLevel)
+	admin.PUT("/log-level", h.SetLogLevel)
+
+	handler.RegisterVersions(public, h.V1())
+	h.RegisterLegacyRoutes(public)
+
+	return router
+}
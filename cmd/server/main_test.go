@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"api/internal/filter"
+	"api/internal/handler"
+	"api/internal/model"
+	"api/internal/openapi"
+	"api/internal/problem"
+)
+
+// stubService is a minimal in-memory data service for router tests.
+type stubService struct{}
+
+func (stubService) All(_ context.Context) iter.Seq[model.Data] {
+	return func(func(model.Data) bool) {}
+}
+
+func (stubService) GetDataByGUID(_ context.Context, _ string) *model.Data { return nil }
+
+func (stubService) Filter(_ context.Context, _ filter.Expr) iter.Seq[model.Data] {
+	return func(func(model.Data) bool) {}
+}
+
+func (stubService) GetDataByGUIDs(_ context.Context, _ []string) map[string]model.Data {
+	return map[string]model.Data{}
+}
+
+func TestSetupRouter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := setupRouter(handler.NewHandler(stubService{}), routerConfig{})
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		expectedCode   string
+		expectedAllow  string
+	}{
+		{name: "health", path: "/health", expectedStatus: http.StatusOK},
+		{name: "version", path: "/version", expectedStatus: http.StatusOK},
+		{name: "list", path: "/v1/schools", expectedStatus: http.StatusOK},
+		{name: "not found", path: "/v1/schools/00000000-0000-0000-0000-000000000000", expectedStatus: http.StatusNotFound, expectedCode: "not_found"},
+		{name: "stats", path: "/v1/stats", expectedStatus: http.StatusOK},
+		{name: "batch without body", method: "POST", path: "/v1/schools/batch", expectedStatus: http.StatusBadRequest, expectedCode: "invalid_body"},
+		{name: "batch method not allowed", method: "DELETE", path: "/v1/schools/batch", expectedStatus: http.StatusMethodNotAllowed, expectedCode: "method_not_allowed", expectedAllow: "GET, POST"},
+		{name: "legacy list", path: "/", expectedStatus: http.StatusOK},
+		{name: "legacy not found", path: "/00000000-0000-0000-0000-000000000000", expectedStatus: http.StatusNotFound, expectedCode: "not_found"},
+		{name: "unknown route", path: "/v1/teams", expectedStatus: http.StatusNotFound, expectedCode: "route_not_found"},
+		{name: "method not allowed", method: "DELETE", path: "/v1/schools", expectedStatus: http.StatusMethodNotAllowed, expectedCode: "method_not_allowed", expectedAllow: "GET"},
+		{name: "admin disabled without token", path: "/admin/log-level", expectedStatus: http.StatusForbidden, expectedCode: "admin_disabled"},
+		{name: "admin method not allowed", method: "POST", path: "/admin/log-level", expectedStatus: http.StatusMethodNotAllowed, expectedCode: "method_not_allowed", expectedAllow: "GET, PUT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = "GET"
+			}
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(method, tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
+			assert.Equal(t, tt.expectedAllow, w.Header().Get("Allow"))
+			if tt.expectedCode != "" {
+				assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+				var p problem.Problem
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+				assert.Equal(t, problem.Code(tt.expectedCode), p.Code)
+				assert.Equal(t, tt.expectedStatus, p.Status)
+				assert.Equal(t, tt.path, p.Instance)
+				assert.Equal(t, w.Header().Get("X-Request-ID"), p.RequestID)
+			}
+		})
+	}
+}
+
+// TestOpenAPIDrift fails when the routes registered by setupRouter and the
+// routes described by the OpenAPI document differ.
+func TestOpenAPIDrift(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := setupRouter(handler.NewHandler(stubService{}), routerConfig{})
+	spec := handler.Spec()
+
+	var registered []openapi.Route
+	for _, r := range router.Routes() {
+		registered = append(registered, openapi.Route{Method: r.Method, Path: openapi.PathTemplate(r.Path)})
+
+		op := spec.Operation(r.Method, r.Path)
+		if op == nil {
+			continue
+		}
+		var declared []string
+		for _, p := range op.Parameters {
+			if p.In == "path" {
+				declared = append(declared, p.Name)
+			}
+		}
+		assert.Equal(t, openapi.PathParams(r.Path), declared, "path parameters of %s %s", r.Method, r.Path)
+	}
+
+	assert.ElementsMatch(t, spec.Routes(), registered, "routes in the OpenAPI document differ from the registered routes")
+}
+
+func TestSetupRouter_AdminToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := setupRouter(handler.NewHandler(stubService{}), routerConfig{
+		adminToken: func() string { return "s3cr3t" },
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/admin/log-level", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/admin/log-level", nil)
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Authentication comes before validation.
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/admin/log-level", strings.NewReader(`{"level":"loud"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/admin/log-level", strings.NewReader(`{"level":"loud"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Non-admin routes stay open
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/health", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,17 @@
+//go:build !unix
+
+package main
+
+import (
+	"context"
+	"os"
+)
+
+// watchLogLevelSignals is a no-op on platforms without SIGUSR1.
+func watchLogLevelSignals(_ context.Context) {}
+
+// notifyUpgrade returns a channel that never receives, since upgrades
+// need SIGUSR2 and descriptor passing.
+func notifyUpgrade() (<-chan os.Signal, func()) {
+	return nil, func() {}
+}
@@ -0,0 +1,40 @@
+//go:build unix
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"api/pkg/logger"
+)
+
+// watchLogLevelSignals cycles the global log level towards debug on
+// SIGUSR1 until ctx is done. The level wraps around from debug to error.
+func watchLogLevelSignals(ctx context.Context) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGUSR1)
+
+	go func() {
+		defer signal.Stop(sigCh)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-sigCh:
+				level := logger.CycleLevel(true)
+				logger.Warn("Log level changed by signal", "signal", sig.String(), "level", level)
+			}
+		}
+	}()
+}
+
+// notifyUpgrade returns a channel receiving SIGUSR2, which requests a
+// zero-downtime upgrade, and a function that stops delivery.
+func notifyUpgrade() (<-chan os.Signal, func()) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGUSR2)
+	return sigCh, func() { signal.Stop(sigCh) }
+}
@@ -2,11 +2,19 @@
 package config
 
 import (
+	"crypto/tls"
+	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	"api/internal/server"
+	"api/pkg/logger"
+
 	"github.com/joho/godotenv"
 )
 
@@ -18,21 +26,110 @@ type Config struct {
 	JSONLog      bool
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
+
+	// Per-component log level overrides, e.g. "service=debug,middleware=warn"
+	LogLevelOverrides string
+
+	// Log outputs, rotation and sampling
+	LogOutputs            []string
+	LogFileMaxSizeMB      int
+	LogFileRotateInterval time.Duration
+	LogFileMaxBackups     int
+	LogFileMaxAge         time.Duration
+	LogSampling           string
+	LogSamplingTick       time.Duration
+
+	// Access log format (slog, combined, json) and output sink
+	AccessLogFormat string
+	AccessLogOutput string
+
+	// Log redaction
+	LogRedactKeys        []string
+	LogRedactQueryParams []string
+	LogRedactHeaders     []string
+	LogHashIPs           bool
+	LogIPHashSalt        string
+
+	// CORS origins allowed to call the API; "*" allows any origin
+	CORSAllowedOrigins []string
+
+	// How often the config file is checked for changes
+	ConfigWatchInterval time.Duration
+
+	// Listeners as scheme://address specs; empty listens on Port over TCP
+	Listeners []string
+
+	// TLS; certificate files are re-read when they change
+	TLSCertFile     string
+	TLSKeyFile      string
+	TLSMinVersion   string
+	TLSCipherSuites []string
+	TLSClientCAFile string
+	TLSClientAuth   string
+
+	// Bearer token required by the /admin endpoints; empty disables them
+	AdminToken string
+
+	// file is the config file the configuration was loaded from, if any.
+	file string
+	// args are the command-line arguments the configuration was loaded with.
+	args []string
+	// sources records the layer that set each field, by field key.
+	sources map[string]string
+	// parseErrs holds values that could not be parsed while loading.
+	parseErrs []error
 }
 
-// Load loads configuration from environment variables with defaults.
+// Load loads configuration from defaults, an optional config file named by
+// CONFIG_FILE and environment variables, in increasing order of precedence.
 // It also attempts to load a .env file if present.
 func Load() (*Config, error) {
+	return LoadArgs(nil)
+}
+
+// LoadArgs loads configuration like Load, additionally applying
+// command-line flags. The precedence is:
+//
+//	defaults < config file < environment (including .env) < flags
+//
+// The config file is named by --config or, failing that, CONFIG_FILE.
+func LoadArgs(args []string) (*Config, error) {
+	return LoadFlagSet(flag.NewFlagSet("server", flag.ContinueOnError), args)
+}
+
+// LoadFlagSet loads configuration like LoadArgs, parsing args with fs.
+// Callers can define their own flags on fs before calling it, e.g. for a
+// subcommand; fs should use flag.ContinueOnError.
+func LoadFlagSet(fs *flag.FlagSet, args []string) (*Config, error) {
+	flags, configPath, err := parseFlags(fs, args)
+	if err != nil {
+		return nil, err
+	}
+
 	// Try to load .env file, but don't fail if it doesn't exist
 	_ = godotenv.Load()
 
+	if configPath == "" {
+		configPath = os.Getenv(ConfigFileEnv)
+	}
+
+	layers := []layer{defaultLayer()}
+	if configPath != "" {
+		file, err := fileLayer(configPath)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, file)
+	}
+	layers = append(layers, envLayer(), flags)
+
 	cfg := &Config{
-		Port:         getEnv("PORT", "3000"),
-		DataFilePath: getEnv("DATA_FILE_PATH", "./data.json"),
-		LogLevel:     getEnv("LOG_LEVEL", "info"),
-		JSONLog:      getEnvBool("JSON_LOG", false),
-		ReadTimeout:  getEnvDuration("READ_TIMEOUT", 10*time.Second),
-		WriteTimeout: getEnvDuration("WRITE_TIMEOUT", 10*time.Second),
+		file:    configPath,
+		args:    args,
+		sources: make(map[string]string, len(fields)),
+	}
+	for _, l := range layers {
+		cfg.apply(l)
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -42,14 +139,89 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
-// Validate validates the configuration values.
+// apply sets every field present in l and records its source. Values that
+// fail to parse are recorded as errors for Validate to report; the field
+// keeps the value from the previous layer.
+func (c *Config) apply(l layer) {
+	c.parseErrs = append(c.parseErrs, l.errs...)
+	for _, f := range fields {
+		value, ok := l.values[f.key]
+		if !ok {
+			continue
+		}
+		if err := f.set(c, value); err != nil {
+			c.parseErrs = append(c.parseErrs, &FieldError{Field: f.key, Source: l.source(f), Err: err})
+			continue
+		}
+		c.sources[f.key] = l.source(f)
+	}
+}
+
+// File returns the path of the config file the configuration was loaded
+// from, or an empty string if none was used.
+func (c *Config) File() string {
+	return c.file
+}
+
+// Source reports which configuration layer set the field with the given
+// key (e.g. "log_level"): "default", "file <path>", "env <VAR>" or
+// "flag --<name>". It returns an empty string for configurations that
+// were not created by Load.
+func (c *Config) Source(key string) string {
+	return c.sources[key]
+}
+
+// FieldError reports an invalid configuration value and its source.
+type FieldError struct {
+	// Field is the configuration key, e.g. "log_level".
+	Field string
+	// Source is the layer that set the value; see Config.Source.
+	Source string
+	// Err describes the problem.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *FieldError) Error() string {
+	if e.Source == "" {
+		return fmt.Sprintf("%s: %v", e.Field, e.Err)
+	}
+	return fmt.Sprintf("%s: %v (from %s)", e.Field, e.Err, e.Source)
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Validate validates the configuration values, including any values that
+// could not be parsed while loading. Every invalid value is reported as a
+// *FieldError naming the layer it came from; multiple errors are joined.
 func (c *Config) Validate() error {
+	errs := append([]error(nil), c.parseErrs...)
+	check := func(key string, err error) {
+		if err != nil {
+			errs = append(errs, &FieldError{Field: key, Source: c.Source(key), Err: err})
+		}
+	}
+
 	if c.Port == "" {
-		return fmt.Errorf("port cannot be empty")
+		check("port", fmt.Errorf("port cannot be empty"))
+	} else if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		check("port", fmt.Errorf("invalid port: %s (must be a number between 1 and 65535)", c.Port))
 	}
 
 	if c.DataFilePath == "" {
-		return fmt.Errorf("data file path cannot be empty")
+		check("data_file_path", fmt.Errorf("data file path cannot be empty"))
+	} else {
+		check("data_file_path", checkReadableFile("data file", c.DataFilePath))
+	}
+
+	if c.ReadTimeout < 0 {
+		check("read_timeout", fmt.Errorf("read timeout cannot be negative"))
+	}
+	if c.WriteTimeout < 0 {
+		check("write_timeout", fmt.Errorf("write timeout cannot be negative"))
 	}
 
 	// Validate log level
@@ -60,36 +232,219 @@ func (c *Config) Validate() error {
 		"error": true,
 	}
 	if !validLogLevels[c.LogLevel] {
-		return fmt.Errorf("invalid log level: %s (must be debug, info, warn, or error)", c.LogLevel)
+		check("log_level", fmt.Errorf("invalid log level: %s (must be debug, info, warn, or error)", c.LogLevel))
 	}
 
+	if _, err := logger.ParseComponentLevels(c.LogLevelOverrides); err != nil {
+		check("log_level_overrides", fmt.Errorf("invalid log level overrides: %w", err))
+	}
+
+	for _, output := range c.LogOutputs {
+		if err := logger.ValidateSink(output); err != nil {
+			check("log_outputs", fmt.Errorf("invalid log output: %w", err))
+		}
+	}
+
+	validAccessLogFormats := map[string]bool{
+		"slog":     true,
+		"combined": true,
+		"json":     true,
+	}
+	if c.AccessLogFormat != "" && !validAccessLogFormats[c.AccessLogFormat] {
+		check("access_log_format", fmt.Errorf("invalid access log format: %s (must be slog, combined, or json)", c.AccessLogFormat))
+	}
+	if c.AccessLogOutput != "" {
+		if err := logger.ValidateSink(c.AccessLogOutput); err != nil {
+			check("access_log_output", fmt.Errorf("invalid access log output: %w", err))
+		}
+	}
+
+	if c.LogFileMaxSizeMB < 0 {
+		check("log_file_max_size_mb", fmt.Errorf("log file size limit cannot be negative"))
+	}
+	if c.LogFileMaxBackups < 0 {
+		check("log_file_max_backups", fmt.Errorf("log file backup limit cannot be negative"))
+	}
+
+	if _, err := logger.ParseSampling(c.LogSampling); err != nil {
+		check("log_sampling", fmt.Errorf("invalid log sampling: %w", err))
+	}
+
+	if c.ConfigWatchInterval < 0 {
+		check("config_watch_interval", fmt.Errorf("config watch interval cannot be negative"))
+	}
+
+	for _, listener := range c.Listeners {
+		if _, err := server.ParseListener(listener); err != nil {
+			check("listeners", err)
+		}
+	}
+
+	c.validateTLS(check)
+
+	return errors.Join(errs...)
+}
+
+// validateTLS reports invalid TLS settings to check.
+func (c *Config) validateTLS(check func(key string, err error)) {
+	switch {
+	case c.TLSCertFile != "" && c.TLSKeyFile == "":
+		check("tls_key_file", fmt.Errorf("TLS key file is required with a certificate file"))
+	case c.TLSCertFile == "" && c.TLSKeyFile != "":
+		check("tls_cert_file", fmt.Errorf("TLS certificate file is required with a key file"))
+	}
+	if c.TLSCertFile != "" {
+		check("tls_cert_file", checkReadableFile("TLS certificate file", c.TLSCertFile))
+	}
+	if c.TLSKeyFile != "" {
+		check("tls_key_file", checkReadableFile("TLS key file", c.TLSKeyFile))
+	}
+
+	if _, err := server.ParseTLSVersion(c.TLSMinVersion); err != nil {
+		check("tls_min_version", err)
+	}
+	if _, err := server.ParseCipherSuites(c.TLSCipherSuites); err != nil {
+		check("tls_cipher_suites", err)
+	}
+
+	clientAuth, err := server.ParseClientAuth(c.TLSClientAuth)
+	if err != nil {
+		check("tls_client_auth", err)
+	} else if clientAuth != tls.NoClientCert && c.TLSClientCAFile == "" {
+		check("tls_client_ca_file", fmt.Errorf("client CA file is required when client auth is %s", c.TLSClientAuth))
+	}
+	if c.TLSClientCAFile != "" {
+		check("tls_client_ca_file", checkReadableFile("client CA file", c.TLSClientCAFile))
+	}
+}
+
+// checkReadableFile reports an error unless path is a regular file that
+// can be opened for reading. what names the file in error messages.
+func checkReadableFile(what, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("%s is not readable: %w", what, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("%s is not readable: %w", what, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s path is a directory: %s", what, path)
+	}
 	return nil
 }
 
-// getEnv retrieves an environment variable or returns a default value.
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// LoggerOptions returns the logger options described by the configuration.
+func (c *Config) LoggerOptions() logger.Options {
+	// Validate has already rejected malformed overrides and sampling rules.
+	componentLevels, _ := logger.ParseComponentLevels(c.LogLevelOverrides)
+	levels, _ := logger.ParseSampling(c.LogSampling)
+
+	return logger.Options{
+		Level:           c.LogLevel,
+		ComponentLevels: componentLevels,
+		JSON:            c.JSONLog,
+		Outputs:         c.LogOutputs,
+		File: logger.FileOptions{
+			MaxSize:        int64(c.LogFileMaxSizeMB) * 1024 * 1024,
+			RotateInterval: c.LogFileRotateInterval,
+			MaxBackups:     c.LogFileMaxBackups,
+			MaxAge:         c.LogFileMaxAge,
+		},
+		Sampling: logger.SamplingOptions{
+			Tick:   c.LogSamplingTick,
+			Levels: levels,
+		},
+		Redact: logger.RedactOptions{
+			Keys:        c.LogRedactKeys,
+			QueryParams: c.LogRedactQueryParams,
+			Headers:     c.LogRedactHeaders,
+			HashIPs:     c.LogHashIPs,
+			IPHashSalt:  c.LogIPHashSalt,
+		},
 	}
-	return defaultValue
 }
 
-// getEnvBool retrieves a boolean environment variable or returns a default value.
-func getEnvBool(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		if parsed, err := strconv.ParseBool(value); err == nil {
-			return parsed
-		}
+// ListenerSpecs returns the listeners described by the configuration:
+// the configured Listeners or, if there are none, TCP on Port.
+func (c *Config) ListenerSpecs() []server.ListenerSpec {
+	if len(c.Listeners) == 0 {
+		return []server.ListenerSpec{{Network: "tcp", Address: ":" + c.Port}}
 	}
-	return defaultValue
+
+	// Validate has already rejected malformed listeners.
+	specs := make([]server.ListenerSpec, 0, len(c.Listeners))
+	for _, listener := range c.Listeners {
+		spec, _ := server.ParseListener(listener)
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// TLSEnabled reports whether the server should serve TLS.
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCertFile != ""
 }
 
-// getEnvDuration retrieves a duration environment variable or returns a default value.
-func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
-		if parsed, err := time.ParseDuration(value); err == nil {
-			return parsed
+// TLSOptions returns the TLS options described by the configuration.
+func (c *Config) TLSOptions() server.TLSOptions {
+	return server.TLSOptions{
+		CertFile:     c.TLSCertFile,
+		KeyFile:      c.TLSKeyFile,
+		MinVersion:   c.TLSMinVersion,
+		CipherSuites: c.TLSCipherSuites,
+		ClientCAFile: c.TLSClientCAFile,
+		ClientAuth:   c.TLSClientAuth,
+	}
+}
+
+// Summary returns the effective configuration as a map suitable for
+// diagnostics output. Secret values are never included.
+func (c *Config) Summary() map[string]any {
+	summary := make(map[string]any, len(fields))
+	for _, f := range fields {
+		if f.secret {
+			continue
 		}
+		summary[f.key] = f.get(c)
+	}
+	return summary
+}
+
+// redactedGet returns the value of f in c, with non-empty secret values
+// replaced by logger.RedactedValue.
+func (c *Config) redactedGet(f field) any {
+	value := f.get(c)
+	if f.secret && value != "" {
+		return logger.RedactedValue
+	}
+	return value
+}
+
+// String renders the configuration as space-separated key=value pairs in
+// field order. Secret values are redacted, so a Config is safe to print.
+func (c *Config) String() string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = fmt.Sprintf("%s=%v", f.key, c.redactedGet(f))
+	}
+	return strings.Join(parts, " ")
+}
+
+// GoString implements fmt.GoStringer so that %#v does not expose secrets.
+func (c *Config) GoString() string {
+	return "config.Config{" + c.String() + "}"
+}
+
+// LogValue implements slog.LogValuer, logging the configuration as a group
+// with secret values redacted.
+func (c *Config) LogValue() slog.Value {
+	attrs := make([]slog.Attr, len(fields))
+	for i, f := range fields {
+		attrs[i] = slog.Any(f.key, c.redactedGet(f))
 	}
-	return defaultValue
+	return slog.GroupValue(attrs...)
 }
@@ -1,11 +1,38 @@
 package config
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
 
+// testDataFile is a readable data file used wherever validation requires one.
+var testDataFile string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "config_test_*")
+	if err != nil {
+		panic(err)
+	}
+	testDataFile = filepath.Join(dir, "data.json")
+	if err := os.WriteFile(testDataFile, []byte("[]"), 0o600); err != nil {
+		panic(err)
+	}
+
+	// Load validates that the data file exists
+	os.Setenv("DATA_FILE_PATH", testDataFile)
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 func TestConfig_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -16,7 +43,7 @@ func TestConfig_Validate(t *testing.T) {
 			name: "valid config",
 			config: &Config{
 				Port:         "3000",
-				DataFilePath: "./data.json",
+				DataFilePath: testDataFile,
 				LogLevel:     "info",
 				JSONLog:      false,
 				ReadTimeout:  10 * time.Second,
@@ -28,7 +55,7 @@ func TestConfig_Validate(t *testing.T) {
 			name: "empty port",
 			config: &Config{
 				Port:         "",
-				DataFilePath: "./data.json",
+				DataFilePath: testDataFile,
 				LogLevel:     "info",
 			},
 			wantErr: true,
@@ -46,16 +73,77 @@ func TestConfig_Validate(t *testing.T) {
 			name: "invalid log level",
 			config: &Config{
 				Port:         "3000",
-				DataFilePath: "./data.json",
+				DataFilePath: testDataFile,
 				LogLevel:     "invalid",
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid log level override",
+			config: &Config{
+				Port:              "3000",
+				DataFilePath:      testDataFile,
+				LogLevel:          "info",
+				LogLevelOverrides: "service=verbose",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid access log format",
+			config: &Config{
+				Port:            "3000",
+				DataFilePath:    testDataFile,
+				LogLevel:        "info",
+				AccessLogFormat: "common",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid access log output",
+			config: &Config{
+				Port:            "3000",
+				DataFilePath:    testDataFile,
+				LogLevel:        "info",
+				AccessLogFormat: "combined",
+				AccessLogOutput: "file:",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid log output",
+			config: &Config{
+				Port:         "3000",
+				DataFilePath: testDataFile,
+				LogLevel:     "info",
+				LogOutputs:   []string{"stdout", "kafka"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid log sampling",
+			config: &Config{
+				Port:         "3000",
+				DataFilePath: testDataFile,
+				LogLevel:     "info",
+				LogSampling:  "info=lots",
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative log file limits",
+			config: &Config{
+				Port:              "3000",
+				DataFilePath:      testDataFile,
+				LogLevel:          "info",
+				LogFileMaxBackups: -1,
+			},
+			wantErr: true,
+		},
 		{
 			name: "valid log levels",
 			config: &Config{
 				Port:         "3000",
-				DataFilePath: "./data.json",
+				DataFilePath: testDataFile,
 				LogLevel:     "debug",
 			},
 			wantErr: false,
@@ -104,6 +192,9 @@ func TestLoad(t *testing.T) {
 	if cfg.LogLevel != "info" {
 		t.Errorf("Load() LogLevel = %v, want %v", cfg.LogLevel, "info")
 	}
+	if len(cfg.LogRedactKeys) == 0 || len(cfg.LogRedactHeaders) == 0 {
+		t.Error("Load() should redact common secrets by default")
+	}
 
 	// Test with environment variables
 	os.Setenv("PORT", "8080")
@@ -119,3 +210,210 @@ func TestLoad(t *testing.T) {
 		t.Errorf("Load() LogLevel = %v, want %v", cfg.LogLevel, "debug")
 	}
 }
+
+func TestConfig_Summary(t *testing.T) {
+	cfg := &Config{
+		Port:         "3000",
+		DataFilePath: testDataFile,
+		LogLevel:     "info",
+		JSONLog:      true,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+
+	summary := cfg.Summary()
+	if summary["port"] != "3000" {
+		t.Errorf("Summary() port = %v, want %v", summary["port"], "3000")
+	}
+	if summary["json_log"] != true {
+		t.Errorf("Summary() json_log = %v, want %v", summary["json_log"], true)
+	}
+	if _, ok := summary["log_ip_hash_salt"]; ok {
+		t.Error("Summary() should not include the IP hash salt")
+	}
+	if summary["read_timeout"] != "5s" {
+		t.Errorf("Summary() read_timeout = %v, want %v", summary["read_timeout"], "5s")
+	}
+}
+
+func TestConfig_RedactedRendering(t *testing.T) {
+	cfg := &Config{Port: "3000", LogIPHashSalt: "pepper", AdminToken: "s3cr3t"}
+
+	var logged bytes.Buffer
+	slog.New(slog.NewTextHandler(&logged, nil)).Info("config", "config", cfg)
+
+	renderings := map[string]string{
+		"String":   cfg.String(),
+		"%v":       fmt.Sprintf("%v", cfg),
+		"%+v":      fmt.Sprintf("%+v", cfg),
+		"%#v":      fmt.Sprintf("%#v", cfg),
+		"LogValue": logged.String(),
+	}
+	for name, out := range renderings {
+		if strings.Contains(out, "s3cr3t") || strings.Contains(out, "pepper") {
+			t.Errorf("%s exposes a secret: %s", name, out)
+		}
+		if !strings.Contains(out, "admin_token=[REDACTED]") || !strings.Contains(out, "port=3000") {
+			t.Errorf("%s = %s, want port shown and admin_token redacted", name, out)
+		}
+	}
+
+	if strings.Contains((&Config{}).String(), "[REDACTED]") {
+		t.Error("String() should not mark unset secrets as redacted")
+	}
+}
+
+func TestConfig_LoggerOptions(t *testing.T) {
+	t.Setenv("LOG_OUTPUTS", "stdout, file:/var/log/api.log")
+	t.Setenv("LOG_FILE_MAX_SIZE_MB", "5")
+	t.Setenv("LOG_SAMPLING", "info=100:10")
+	t.Setenv("LOG_LEVEL_OVERRIDES", "service=debug")
+	t.Setenv("LOG_HASH_IPS", "true")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	opts := cfg.LoggerOptions()
+	if len(opts.Outputs) != 2 || opts.Outputs[1] != "file:/var/log/api.log" {
+		t.Errorf("LoggerOptions() Outputs = %v", opts.Outputs)
+	}
+	if opts.File.MaxSize != 5*1024*1024 {
+		t.Errorf("LoggerOptions() File.MaxSize = %v, want %v", opts.File.MaxSize, 5*1024*1024)
+	}
+	if len(opts.ComponentLevels) != 1 {
+		t.Errorf("LoggerOptions() ComponentLevels = %v, want one override", opts.ComponentLevels)
+	}
+	if !opts.Redact.HashIPs {
+		t.Error("LoggerOptions() Redact.HashIPs = false, want true")
+	}
+	if len(opts.Sampling.Levels) != 1 {
+		t.Errorf("LoggerOptions() Sampling.Levels = %v, want one rule", opts.Sampling.Levels)
+	}
+}
+
+func TestLoad_RejectsMalformedValues(t *testing.T) {
+	t.Setenv("READ_TIMEOUT", "10 seconds")
+	t.Setenv("JSON_LOG", "yes please")
+	t.Setenv("LOG_FILE_MAX_BACKUPS", "seven")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("Load() expected error for malformed values")
+	}
+
+	for _, want := range []string{
+		`read_timeout: invalid duration "10 seconds" (from env READ_TIMEOUT)`,
+		`json_log: invalid boolean "yes please" (from env JSON_LOG)`,
+		`log_file_max_backups: invalid integer "seven" (from env LOG_FILE_MAX_BACKUPS)`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Load() error = %v, want containing %q", err, want)
+		}
+	}
+
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) {
+		t.Errorf("Load() error = %v, want a *FieldError", err)
+	}
+}
+
+func TestConfig_ValidatePortAndDataFile(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		port     string
+		dataFile string
+		wantErr  string
+	}{
+		{name: "valid", port: "8080", dataFile: testDataFile},
+		{name: "non-numeric port", port: "http", dataFile: testDataFile, wantErr: "invalid port: http"},
+		{name: "port out of range", port: "70000", dataFile: testDataFile, wantErr: "invalid port: 70000"},
+		{name: "port zero", port: "0", dataFile: testDataFile, wantErr: "invalid port: 0"},
+		{name: "missing data file", port: "8080", dataFile: filepath.Join(dir, "missing.json"), wantErr: "data file is not readable"},
+		{name: "data file is a directory", port: "8080", dataFile: dir, wantErr: "data file path is a directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Port: tt.port, DataFilePath: tt.dataFile, LogLevel: "info"}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateTLS(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr string
+	}{
+		{name: "disabled", modify: func(c *Config) {}},
+		{name: "enabled", modify: func(c *Config) {
+			c.TLSCertFile, c.TLSKeyFile = testDataFile, testDataFile
+		}},
+		{name: "mutual TLS", modify: func(c *Config) {
+			c.TLSCertFile, c.TLSKeyFile, c.TLSClientCAFile, c.TLSClientAuth = testDataFile, testDataFile, testDataFile, "require"
+		}},
+		{name: "cert without key", modify: func(c *Config) { c.TLSCertFile = testDataFile }, wantErr: "tls_key_file: TLS key file is required"},
+		{name: "missing cert file", modify: func(c *Config) {
+			c.TLSCertFile, c.TLSKeyFile = filepath.Join(dir, "missing.pem"), testDataFile
+		}, wantErr: "TLS certificate file is not readable"},
+		{name: "bad version", modify: func(c *Config) { c.TLSMinVersion = "1.1" }, wantErr: "unsupported TLS version"},
+		{name: "bad cipher", modify: func(c *Config) { c.TLSCipherSuites = []string{"TLS_NULL"} }, wantErr: "unknown or insecure cipher suite"},
+		{name: "bad client auth", modify: func(c *Config) { c.TLSClientAuth = "always" }, wantErr: "invalid client auth"},
+		{name: "client auth without CA", modify: func(c *Config) { c.TLSClientAuth = "optional" }, wantErr: "client CA file is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Port: "8080", DataFilePath: testDataFile, LogLevel: "info"}
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_ListenerSpecs(t *testing.T) {
+	cfg := &Config{Port: "8080", DataFilePath: testDataFile, LogLevel: "info"}
+	specs := cfg.ListenerSpecs()
+	if len(specs) != 1 || specs[0].String() != "tcp://:8080" {
+		t.Errorf("ListenerSpecs() = %v, want tcp://:8080 from the port", specs)
+	}
+
+	cfg.Listeners = []string{"tcp://127.0.0.1:8080", "h2c+unix:///run/api.sock?mode=0660"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	specs = cfg.ListenerSpecs()
+	if len(specs) != 2 || !specs[1].H2C || specs[1].Mode != 0o660 {
+		t.Errorf("ListenerSpecs() = %+v", specs)
+	}
+
+	cfg.Listeners = []string{"udp://:53"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "listeners: invalid listener") {
+		t.Errorf("Validate() error = %v, want invalid listener", err)
+	}
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field describes one configuration setting and how it is read from each
+// configuration layer.
+type field struct {
+	// key is the name used in config files; flags use it with dashes.
+	key string
+	// env is the environment variable name.
+	env string
+	// def is the default value in its string form.
+	def string
+	// usage describes the setting for --help output.
+	usage string
+	// secret marks values that must never be printed or logged.
+	secret bool
+	// boolean marks settings that may be given as a bare command-line flag.
+	boolean bool
+	// reloadable marks settings that can be applied without a restart.
+	reloadable bool
+	// set parses value into the field of c.
+	set func(c *Config, value string) error
+	// get returns the typed value of the field in c.
+	get func(c *Config) any
+	// copy copies the field from src to dst.
+	copy func(dst, src *Config)
+}
+
+// flagName returns the command-line flag name for the field.
+func (f field) flagName() string {
+	return strings.ReplaceAll(f.key, "_", "-")
+}
+
+// fields lists every configuration setting in documentation order.
+var fields = []field{
+	stringField("port", "PORT", "3000", "port number for the server to listen on",
+		func(c *Config) *string { return &c.Port }),
+	stringField("data_file_path", "DATA_FILE_PATH", "./data.json", "path to the JSON data file",
+		func(c *Config) *string { return &c.DataFilePath }),
+	reloadableField(stringField("log_level", "LOG_LEVEL", "info", "logging level (debug, info, warn, error)",
+		func(c *Config) *string { return &c.LogLevel })),
+	reloadableField(boolField("json_log", "JSON_LOG", "false", "enable JSON log output format",
+		func(c *Config) *bool { return &c.JSONLog })),
+	durationField("read_timeout", "READ_TIMEOUT", "10s", "HTTP read timeout",
+		func(c *Config) *time.Duration { return &c.ReadTimeout }),
+	durationField("write_timeout", "WRITE_TIMEOUT", "10s", "HTTP write timeout",
+		func(c *Config) *time.Duration { return &c.WriteTimeout }),
+
+	reloadableField(stringField("log_level_overrides", "LOG_LEVEL_OVERRIDES", "", "per-component log levels, e.g. service=debug,middleware=warn",
+		func(c *Config) *string { return &c.LogLevelOverrides })),
+
+	reloadableField(listField("log_outputs", "LOG_OUTPUTS", "stdout", "comma-separated log outputs (stdout, stderr, file:<path>, syslog[:<socket>])",
+		func(c *Config) *[]string { return &c.LogOutputs })),
+	reloadableField(intField("log_file_max_size_mb", "LOG_FILE_MAX_SIZE_MB", "100", "rotate log files after this size in MB (0 disables)",
+		func(c *Config) *int { return &c.LogFileMaxSizeMB })),
+	reloadableField(durationField("log_file_rotate_interval", "LOG_FILE_ROTATE_INTERVAL", "0s", "rotate log files after this age (0 disables)",
+		func(c *Config) *time.Duration { return &c.LogFileRotateInterval })),
+	reloadableField(intField("log_file_max_backups", "LOG_FILE_MAX_BACKUPS", "7", "number of rotated log files to keep (0 keeps all)",
+		func(c *Config) *int { return &c.LogFileMaxBackups })),
+	reloadableField(durationField("log_file_max_age", "LOG_FILE_MAX_AGE", "0s", "delete rotated log files older than this (0 keeps all)",
+		func(c *Config) *time.Duration { return &c.LogFileMaxAge })),
+	reloadableField(stringField("log_sampling", "LOG_SAMPLING", "", "per-level sampling rules, e.g. info=100:10",
+		func(c *Config) *string { return &c.LogSampling })),
+	reloadableField(durationField("log_sampling_tick", "LOG_SAMPLING_TICK", "1s", "window after which sampling counters reset",
+		func(c *Config) *time.Duration { return &c.LogSamplingTick })),
+
+	stringField("access_log_format", "ACCESS_LOG_FORMAT", "slog", "request log format (slog, combined, json)",
+		func(c *Config) *string { return &c.AccessLogFormat }),
+	stringField("access_log_output", "ACCESS_LOG_OUTPUT", "stdout", "output for combined and json access logs",
+		func(c *Config) *string { return &c.AccessLogOutput }),
+
+	reloadableField(listField("log_redact_keys", "LOG_REDACT_KEYS", "password,secret,token,api_key,authorization", "log attribute keys whose values are masked",
+		func(c *Config) *[]string { return &c.LogRedactKeys })),
+	reloadableField(listField("log_redact_query_params", "LOG_REDACT_QUERY_PARAMS", "token,access_token,api_key,password", "query parameters masked in logs",
+		func(c *Config) *[]string { return &c.LogRedactQueryParams })),
+	reloadableField(listField("log_redact_headers", "LOG_REDACT_HEADERS", "Authorization,Cookie,Set-Cookie,X-Api-Key", "HTTP headers masked in logs",
+		func(c *Config) *[]string { return &c.LogRedactHeaders })),
+	reloadableField(boolField("log_hash_ips", "LOG_HASH_IPS", "false", "replace client IPs in logs with a salted hash",
+		func(c *Config) *bool { return &c.LogHashIPs })),
+	reloadableField(secretField(stringField("log_ip_hash_salt", "LOG_IP_HASH_SALT", "", "salt mixed into IP hashes",
+		func(c *Config) *string { return &c.LogIPHashSalt }))),
+
+	reloadableField(listField("cors_allowed_origins", "CORS_ALLOWED_ORIGINS", "*", "comma-separated origins allowed by CORS (* allows any)",
+		func(c *Config) *[]string { return &c.CORSAllowedOrigins })),
+
+	reloadableField(durationField("config_watch_interval", "CONFIG_WATCH_INTERVAL", "5s", "how often to check the config file for changes (0 disables)",
+		func(c *Config) *time.Duration { return &c.ConfigWatchInterval })),
+
+	listField("listeners", "LISTENERS", "", "comma-separated listeners: tcp://host:port, h2c://host:port, unix:///path[?mode=0660], h2c+unix:///path (default tcp://:<port>)",
+		func(c *Config) *[]string { return &c.Listeners }),
+
+	stringField("tls_cert_file", "TLS_CERT_FILE", "", "PEM certificate chain; enables TLS",
+		func(c *Config) *string { return &c.TLSCertFile }),
+	stringField("tls_key_file", "TLS_KEY_FILE", "", "PEM private key for the TLS certificate",
+		func(c *Config) *string { return &c.TLSKeyFile }),
+	stringField("tls_min_version", "TLS_MIN_VERSION", "1.2", "minimum TLS version (1.2, 1.3)",
+		func(c *Config) *string { return &c.TLSMinVersion }),
+	listField("tls_cipher_suites", "TLS_CIPHER_SUITES", "", "comma-separated TLS 1.2 cipher suites (empty uses Go defaults)",
+		func(c *Config) *[]string { return &c.TLSCipherSuites }),
+	stringField("tls_client_ca_file", "TLS_CLIENT_CA_FILE", "", "PEM CA bundle client certificates are verified against",
+		func(c *Config) *string { return &c.TLSClientCAFile }),
+	stringField("tls_client_auth", "TLS_CLIENT_AUTH", "none", "client certificate policy (none, optional, require)",
+		func(c *Config) *string { return &c.TLSClientAuth }),
+
+	reloadableField(secretField(stringField("admin_token", "ADMIN_TOKEN", "", "bearer token required by the /admin endpoints (empty disables them)",
+		func(c *Config) *string { return &c.AdminToken }))),
+}
+
+// lookupField returns the field with the given key.
+func lookupField(key string) (field, bool) {
+	for _, f := range fields {
+		if f.key == key {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// stringField describes a string setting.
+func stringField(key, env, def, usage string, ptr func(*Config) *string) field {
+	return field{
+		key: key, env: env, def: def, usage: usage,
+		set: func(c *Config, value string) error {
+			*ptr(c) = value
+			return nil
+		},
+		get:  func(c *Config) any { return *ptr(c) },
+		copy: func(dst, src *Config) { *ptr(dst) = *ptr(src) },
+	}
+}
+
+// boolField describes a boolean setting.
+func boolField(key, env, def, usage string, ptr func(*Config) *bool) field {
+	return field{
+		key: key, env: env, def: def, usage: usage, boolean: true,
+		set: func(c *Config, value string) error {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid boolean %q", value)
+			}
+			*ptr(c) = parsed
+			return nil
+		},
+		get:  func(c *Config) any { return *ptr(c) },
+		copy: func(dst, src *Config) { *ptr(dst) = *ptr(src) },
+	}
+}
+
+// intField describes an integer setting.
+func intField(key, env, def, usage string, ptr func(*Config) *int) field {
+	return field{
+		key: key, env: env, def: def, usage: usage,
+		set: func(c *Config, value string) error {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid integer %q", value)
+			}
+			*ptr(c) = parsed
+			return nil
+		},
+		get:  func(c *Config) any { return *ptr(c) },
+		copy: func(dst, src *Config) { *ptr(dst) = *ptr(src) },
+	}
+}
+
+// durationField describes a duration setting such as "10s".
+func durationField(key, env, def, usage string, ptr func(*Config) *time.Duration) field {
+	return field{
+		key: key, env: env, def: def, usage: usage,
+		set: func(c *Config, value string) error {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid duration %q", value)
+			}
+			*ptr(c) = parsed
+			return nil
+		},
+		get:  func(c *Config) any { return ptr(c).String() },
+		copy: func(dst, src *Config) { *ptr(dst) = *ptr(src) },
+	}
+}
+
+// listField describes a comma-separated list setting. Empty items are ignored.
+func listField(key, env, def, usage string, ptr func(*Config) *[]string) field {
+	return field{
+		key: key, env: env, def: def, usage: usage,
+		set: func(c *Config, value string) error {
+			var items []string
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			*ptr(c) = items
+			return nil
+		},
+		get:  func(c *Config) any { return *ptr(c) },
+		copy: func(dst, src *Config) { *ptr(dst) = *ptr(src) },
+	}
+}
+
+// reloadableField marks f as safe to change without a restart.
+func reloadableField(f field) field {
+	f.reloadable = true
+	return f
+}
+
+// secretField marks f as holding a secret value.
+func secretField(f field) field {
+	f.secret = true
+	return f
+}
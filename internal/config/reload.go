@@ -0,0 +1,245 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"api/pkg/logger"
+)
+
+// Change describes one setting that differs between two configurations.
+type Change struct {
+	// Field is the configuration key, e.g. "log_level".
+	Field string
+	// Old and New are the values before and after. Secret values are
+	// replaced by logger.RedactedValue.
+	Old any
+	New any
+	// Reloadable reports whether the change can be applied without a restart.
+	Reloadable bool
+}
+
+// Diff returns the settings that differ between old and new, in
+// documentation order.
+func Diff(old, new *Config) []Change {
+	var changes []Change
+	for _, f := range fields {
+		oldValue, newValue := f.get(old), f.get(new)
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		if f.secret {
+			oldValue, newValue = logger.RedactedValue, logger.RedactedValue
+		}
+		changes = append(changes, Change{
+			Field:      f.key,
+			Old:        oldValue,
+			New:        newValue,
+			Reloadable: f.reloadable,
+		})
+	}
+	return changes
+}
+
+// ReloadFunc applies a reloaded configuration to a running component.
+type ReloadFunc func(old, new *Config) error
+
+// ReloadNotifier is told when a reload starts and when the server is ready
+// again, e.g. to report reloads to a service manager.
+type ReloadNotifier interface {
+	Reloading() error
+	Ready() error
+}
+
+// Reloader holds the live configuration and reloads it on demand, on
+// SIGHUP, and when the config file changes. Only reloadable settings take
+// effect; other changes are reported but keep their running values until
+// the process is restarted.
+type Reloader struct {
+	mu       sync.Mutex
+	current  atomic.Pointer[Config]
+	hooks    []ReloadFunc
+	notifier ReloadNotifier
+	fileMod  time.Time
+}
+
+// NewReloader creates a reloader whose live configuration is cfg.
+func NewReloader(cfg *Config) *Reloader {
+	r := &Reloader{}
+	r.current.Store(cfg)
+	r.fileMod = modTime(cfg.File())
+	return r
+}
+
+// Current returns the live configuration. It is safe for concurrent use.
+func (r *Reloader) Current() *Config {
+	return r.current.Load()
+}
+
+// OnReload registers fn to be called, in registration order, whenever a
+// reloaded configuration is applied. If a later hook fails, fn is called
+// again with the arguments swapped to restore the previous configuration,
+// so hooks that can fail are best registered first.
+func (r *Reloader) OnReload(fn ReloadFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, fn)
+}
+
+// SetNotifier sets n to be told when each reload starts and ends.
+// Notification errors do not affect the reload.
+func (r *Reloader) SetNotifier(n ReloadNotifier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notifier = n
+}
+
+// Reload loads the configuration again from the same file, environment
+// and arguments, applies the reloadable changes and returns every change
+// found. If loading, validation or a hook fails the live configuration
+// is left unchanged, and the hooks already run are rolled back.
+func (r *Reloader) Reload() ([]Change, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.notifier != nil {
+		_ = r.notifier.Reloading()
+		defer func() { _ = r.notifier.Ready() }()
+	}
+
+	old := r.current.Load()
+	next, err := LoadArgs(old.args)
+	if err != nil {
+		return nil, err
+	}
+	r.fileMod = modTime(next.File())
+
+	changes := Diff(old, next)
+
+	// Settings that need a restart keep their running values.
+	applied := *next
+	applied.sources = make(map[string]string, len(next.sources))
+	for key, source := range next.sources {
+		applied.sources[key] = source
+	}
+	for _, change := range changes {
+		if change.Reloadable {
+			continue
+		}
+		f, _ := lookupField(change.Field)
+		f.copy(&applied, old)
+		applied.sources[f.key] = old.sources[f.key]
+	}
+
+	for i, hook := range r.hooks {
+		if err := hook(old, &applied); err != nil {
+			err = fmt.Errorf("could not apply configuration: %w", err)
+			for j := i - 1; j >= 0; j-- {
+				if rollbackErr := r.hooks[j](&applied, old); rollbackErr != nil {
+					err = errors.Join(err, fmt.Errorf("could not restore configuration: %w", rollbackErr))
+				}
+			}
+			return changes, err
+		}
+	}
+
+	r.current.Store(&applied)
+	return changes, nil
+}
+
+// Watch reloads the configuration on SIGHUP and whenever the config file's
+// modification time changes, checking every ConfigWatchInterval, until ctx
+// is done. Every change is logged, with a warning for those requiring a
+// restart. SIGHUP is caught from when Watch returns; the watching happens
+// in the background.
+func (r *Reloader) Watch(ctx context.Context) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	go r.watch(ctx, sigCh)
+}
+
+// watch implements Watch, reloading on each signal received on sigCh.
+func (r *Reloader) watch(ctx context.Context, sigCh chan os.Signal) {
+	defer signal.Stop(sigCh)
+
+	for {
+		// The interval is re-read each time since it is itself reloadable.
+		var (
+			timer *time.Timer
+			tick  <-chan time.Time
+		)
+		if cfg := r.Current(); cfg.File() != "" && cfg.ConfigWatchInterval > 0 {
+			timer = time.NewTimer(cfg.ConfigWatchInterval)
+			tick = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case <-sigCh:
+			r.reloadAndLog(ctx, "signal")
+		case <-tick:
+			if r.fileChanged() {
+				r.reloadAndLog(ctx, "file change")
+			}
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// fileChanged reports whether the config file was modified since it was
+// last loaded.
+func (r *Reloader) fileChanged() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mod := modTime(r.current.Load().File())
+	return !mod.IsZero() && !mod.Equal(r.fileMod)
+}
+
+// reloadAndLog reloads the configuration and logs the outcome. The logger
+// is taken after the reload, which may have replaced the log outputs.
+func (r *Reloader) reloadAndLog(ctx context.Context, trigger string) {
+	changes, err := r.Reload()
+	log := logger.Component(ctx, "config")
+	if err != nil {
+		log.Error("Configuration reload failed; keeping current configuration", "trigger", trigger, "error", err)
+		return
+	}
+
+	for _, change := range changes {
+		if change.Reloadable {
+			log.Info("Configuration changed", "field", change.Field, "old", change.Old, "new", change.New)
+		} else {
+			log.Warn("Configuration change requires restart", "field", change.Field, "old", change.Old, "new", change.New)
+		}
+	}
+	log.Info("Configuration reloaded", "trigger", trigger, "changes", len(changes))
+}
+
+// modTime returns the modification time of path, or the zero time if it
+// cannot be determined.
+func modTime(path string) time.Time {
+	if path == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
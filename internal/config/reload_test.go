@@ -0,0 +1,248 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	old := &Config{Port: "3000", LogLevel: "info", LogIPHashSalt: "a", CORSAllowedOrigins: []string{"*"}}
+	next := &Config{Port: "4000", LogLevel: "debug", LogIPHashSalt: "b", CORSAllowedOrigins: []string{"*"}}
+
+	changes := Diff(old, next)
+	byField := make(map[string]Change)
+	for _, change := range changes {
+		byField[change.Field] = change
+	}
+
+	if len(changes) != 3 {
+		t.Fatalf("Diff() = %v, want 3 changes", changes)
+	}
+	if c := byField["port"]; c.Reloadable || c.Old != "3000" || c.New != "4000" {
+		t.Errorf("Diff() port = %+v, want non-reloadable 3000 -> 4000", c)
+	}
+	if c := byField["log_level"]; !c.Reloadable {
+		t.Errorf("Diff() log_level = %+v, want reloadable", c)
+	}
+	if c := byField["log_ip_hash_salt"]; c.Old == "a" || c.New == "b" {
+		t.Errorf("Diff() log_ip_hash_salt = %+v, secret values must not be exposed", c)
+	}
+}
+
+func TestReloader_Reload(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "port: 3000\nlog_level: info\ncors_allowed_origins: [\"*\"]\n")
+
+	cfg, err := LoadArgs([]string{"--config", path})
+	if err != nil {
+		t.Fatalf("LoadArgs() error = %v", err)
+	}
+	r := NewReloader(cfg)
+
+	var applied atomic.Pointer[Config]
+	r.OnReload(func(_, next *Config) error {
+		applied.Store(next)
+		return nil
+	})
+
+	writeFile(t, path, "port: 4000\nlog_level: debug\ncors_allowed_origins: [https://example.com]\n")
+
+	changes, err := r.Reload()
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if len(changes) != 3 {
+		t.Errorf("Reload() changes = %v, want 3", changes)
+	}
+
+	current := r.Current()
+	if current.LogLevel != "debug" {
+		t.Errorf("Current().LogLevel = %v, want debug", current.LogLevel)
+	}
+	if len(current.CORSAllowedOrigins) != 1 || current.CORSAllowedOrigins[0] != "https://example.com" {
+		t.Errorf("Current().CORSAllowedOrigins = %v", current.CORSAllowedOrigins)
+	}
+	if current.Port != "3000" {
+		t.Errorf("Current().Port = %v, restart-only settings must keep their running value", current.Port)
+	}
+	if applied.Load() != current {
+		t.Error("OnReload hook should receive the applied configuration")
+	}
+}
+
+func TestReloader_ReloadFailureKeepsCurrent(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "log_level: info\n")
+	cfg, err := LoadArgs([]string{"--config", path})
+	if err != nil {
+		t.Fatalf("LoadArgs() error = %v", err)
+	}
+	r := NewReloader(cfg)
+
+	// Invalid file content
+	writeFile(t, path, "log_level: loud\n")
+	if _, err := r.Reload(); err == nil {
+		t.Error("Reload() expected validation error")
+	}
+	if r.Current() != cfg {
+		t.Error("Current() should be unchanged after a failed reload")
+	}
+
+	// Failing hook
+	writeFile(t, path, "log_level: warn\n")
+	r.OnReload(func(_, _ *Config) error { return errors.New("sink unavailable") })
+	if _, err := r.Reload(); err == nil || !strings.Contains(err.Error(), "sink unavailable") {
+		t.Errorf("Reload() error = %v, want hook error", err)
+	}
+	if r.Current().LogLevel != "info" {
+		t.Errorf("Current().LogLevel = %v, want unchanged info", r.Current().LogLevel)
+	}
+}
+
+func TestReloader_ReloadFailureRollsBackHooks(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "log_level: info\n")
+	cfg, err := LoadArgs([]string{"--config", path})
+	if err != nil {
+		t.Fatalf("LoadArgs() error = %v", err)
+	}
+	r := NewReloader(cfg)
+
+	// The first hook applies the level; the second fails.
+	live := cfg.LogLevel
+	var calls []string
+	r.OnReload(func(_, next *Config) error {
+		live = next.LogLevel
+		calls = append(calls, next.LogLevel)
+		return nil
+	})
+	r.OnReload(func(_, _ *Config) error { return errors.New("data file unreadable") })
+
+	writeFile(t, path, "log_level: debug\n")
+	if _, err := r.Reload(); err == nil || !strings.Contains(err.Error(), "data file unreadable") {
+		t.Fatalf("Reload() error = %v, want hook error", err)
+	}
+	if live != "info" {
+		t.Errorf("applied level = %v after failed reload, want info restored", live)
+	}
+	if got := strings.Join(calls, ","); got != "debug,info" {
+		t.Errorf("first hook applied %s, want debug,info", got)
+	}
+	if r.Current() != cfg {
+		t.Error("Current() should be unchanged after a failed reload")
+	}
+}
+
+func TestReloader_WatchFileChange(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "log_level: info\nconfig_watch_interval: 10ms\n")
+	cfg, err := LoadArgs([]string{"--config", path})
+	if err != nil {
+		t.Fatalf("LoadArgs() error = %v", err)
+	}
+	r := NewReloader(cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.Watch(ctx)
+
+	writeFile(t, path, "log_level: error\nconfig_watch_interval: 10ms\n")
+	// Make sure the modification time differs on coarse-grained filesystems.
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for r.Current().LogLevel != "error" {
+		if time.Now().After(deadline) {
+			t.Fatal("Watch() did not reload after the config file changed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReloader_WatchSignal(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "log_level: info\n")
+	cfg, err := LoadArgs([]string{"--config", path})
+	if err != nil {
+		t.Fatalf("LoadArgs() error = %v", err)
+	}
+	r := NewReloader(cfg)
+	reloaded := make(chan struct{}, 1)
+	r.OnReload(func(_, _ *Config) error {
+		reloaded <- struct{}{}
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.Watch(ctx)
+
+	// SIGHUP is caught as soon as Watch returns; otherwise it would end
+	// the test binary.
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("Kill() error = %v", err)
+	}
+	select {
+	case <-reloaded:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Watch() did not reload on SIGHUP")
+	}
+}
+
+// writeFile replaces the content of path.
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+// recordingNotifier records reload notifications.
+type recordingNotifier struct {
+	events []string
+}
+
+func (n *recordingNotifier) Reloading() error {
+	n.events = append(n.events, "reloading")
+	return nil
+}
+
+func (n *recordingNotifier) Ready() error {
+	n.events = append(n.events, "ready")
+	return nil
+}
+
+func TestReloader_Notifier(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "log_level: info\n")
+	cfg, err := LoadArgs([]string{"--config", path})
+	if err != nil {
+		t.Fatalf("LoadArgs() error = %v", err)
+	}
+	r := NewReloader(cfg)
+
+	notifier := &recordingNotifier{}
+	r.SetNotifier(notifier)
+	r.OnReload(func(_, _ *Config) error {
+		notifier.events = append(notifier.events, "hook")
+		return nil
+	})
+
+	if _, err := r.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	// A failed reload still reports the server as ready again.
+	writeFile(t, path, "log_level: loud\n")
+	if _, err := r.Reload(); err == nil {
+		t.Fatal("Reload() expected error")
+	}
+
+	want := []string{"reloading", "hook", "ready", "reloading", "ready"}
+	if strings.Join(notifier.events, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %v, want %v", notifier.events, want)
+	}
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats accepted by Render.
+const (
+	FormatEnv  = "env"
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// Render renders the effective configuration in field order with secret
+// values redacted. FormatEnv writes VAR=value lines; FormatYAML and
+// FormatJSON write the config file keys, so the output can be used as a
+// config file once secrets are filled in.
+func (c *Config) Render(format string) ([]byte, error) {
+	switch format {
+	case FormatEnv:
+		return c.renderEnv(), nil
+	case FormatYAML:
+		return c.renderYAML()
+	case FormatJSON:
+		return c.renderJSON()
+	default:
+		return nil, fmt.Errorf("unsupported format %q (must be env, yaml, or json)", format)
+	}
+}
+
+// renderEnv renders the configuration as environment variable assignments.
+// Values that a shell or .env parser would split are quoted.
+func (c *Config) renderEnv() []byte {
+	var buf bytes.Buffer
+	for _, f := range fields {
+		value := envValueString(c.redactedGet(f))
+		if strings.ContainsAny(value, " \t\n\"'\\$#") {
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(&buf, "%s=%s\n", f.env, value)
+	}
+	return buf.Bytes()
+}
+
+// envValueString converts a field value to its environment variable form.
+func envValueString(value any) string {
+	if items, ok := value.([]string); ok {
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+// renderYAML renders the configuration as a YAML mapping.
+func (c *Config) renderYAML() ([]byte, error) {
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	for _, f := range fields {
+		value := &yaml.Node{}
+		if err := value.Encode(c.redactedGet(f)); err != nil {
+			return nil, fmt.Errorf("could not encode %s: %w", f.key, err)
+		}
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: f.key}, value)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, fmt.Errorf("could not encode configuration: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("could not encode configuration: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderJSON renders the configuration as an indented JSON object. Keys
+// are written in field order rather than sorted.
+func (c *Config) renderJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{\n")
+	for i, f := range fields {
+		value := c.redactedGet(f)
+		if items, ok := value.([]string); ok && items == nil {
+			value = []string{}
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("could not encode %s: %w", f.key, err)
+		}
+		fmt.Fprintf(&buf, "  %q: %s", f.key, encoded)
+		if i < len(fields)-1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
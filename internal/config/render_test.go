@@ -0,0 +1,89 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfig_Render(t *testing.T) {
+	cfg := &Config{
+		Port:               "3000",
+		LogLevel:           "info",
+		LogOutputs:         []string{"stdout", "file:/var/log/api.log"},
+		LogSampling:        "info=100:10 debug=1:0",
+		CORSAllowedOrigins: []string{"*"},
+		AdminToken:         "s3cr3t",
+	}
+
+	env, err := cfg.Render(FormatEnv)
+	if err != nil {
+		t.Fatalf("Render(env) error = %v", err)
+	}
+	for _, want := range []string{
+		"PORT=3000\n",
+		"LOG_OUTPUTS=stdout,file:/var/log/api.log\n",
+		`LOG_SAMPLING="info=100:10 debug=1:0"` + "\n",
+		"ADMIN_TOKEN=[REDACTED]\n",
+		"LOG_IP_HASH_SALT=\n",
+	} {
+		if !strings.Contains(string(env), want) {
+			t.Errorf("Render(env) = %s, want line %q", env, want)
+		}
+	}
+	if !strings.HasPrefix(string(env), "PORT=") {
+		t.Errorf("Render(env) should list fields in order, got %s", env)
+	}
+
+	out, err := cfg.Render(FormatYAML)
+	if err != nil {
+		t.Fatalf("Render(yaml) error = %v", err)
+	}
+	var fromYAML map[string]any
+	if err := yaml.Unmarshal(out, &fromYAML); err != nil {
+		t.Fatalf("Render(yaml) produced invalid YAML: %v\n%s", err, out)
+	}
+	if fromYAML["port"] != "3000" || fromYAML["admin_token"] != "[REDACTED]" {
+		t.Errorf("Render(yaml) = %s", out)
+	}
+
+	out, err = cfg.Render(FormatJSON)
+	if err != nil {
+		t.Fatalf("Render(json) error = %v", err)
+	}
+	var fromJSON map[string]any
+	if err := json.Unmarshal(out, &fromJSON); err != nil {
+		t.Fatalf("Render(json) produced invalid JSON: %v\n%s", err, out)
+	}
+	if len(fromJSON) != len(fields) || fromJSON["admin_token"] != "[REDACTED]" {
+		t.Errorf("Render(json) = %s", out)
+	}
+	if _, ok := fromJSON["log_redact_keys"].([]any); !ok {
+		t.Errorf("Render(json) log_redact_keys = %v, want an array", fromJSON["log_redact_keys"])
+	}
+
+	if _, err := cfg.Render("xml"); err == nil {
+		t.Error("Render(xml) expected error")
+	}
+}
+
+func TestConfig_RenderRoundTrip(t *testing.T) {
+	cfg, err := LoadArgs([]string{"--log-level", "warn", "--log-outputs", "stdout,stderr"})
+	if err != nil {
+		t.Fatalf("LoadArgs() error = %v", err)
+	}
+
+	out, err := cfg.Render(FormatYAML)
+	if err != nil {
+		t.Fatalf("Render(yaml) error = %v", err)
+	}
+	reloaded, err := LoadArgs([]string{"--config", writeConfigFile(t, "config.yaml", string(out))})
+	if err != nil {
+		t.Fatalf("LoadArgs() of rendered config error = %v\n%s", err, out)
+	}
+	if reloaded.String() != cfg.String() {
+		t.Errorf("round trip changed the configuration:\n got %s\nwant %s", reloaded, cfg)
+	}
+}
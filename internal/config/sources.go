@@ -0,0 +1,293 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// SourceDefault is the source reported for values that were not set by
+// any configuration layer.
+const SourceDefault = "default"
+
+// ConfigFileEnv is the environment variable naming the config file when
+// the --config flag is not given.
+const ConfigFileEnv = "CONFIG_FILE"
+
+// FileEnvSuffix is appended to a setting's environment variable to read
+// its value from a file instead, e.g. ADMIN_TOKEN_FILE=/run/secrets/token.
+const FileEnvSuffix = "_FILE"
+
+// layer holds raw values, keyed by field key, from one configuration source.
+type layer struct {
+	values map[string]string
+	// source describes where the value of f came from.
+	source func(f field) string
+	// errs holds values that could not be read from the source.
+	errs []error
+}
+
+// defaultLayer returns the built-in default of every field.
+func defaultLayer() layer {
+	values := make(map[string]string, len(fields))
+	for _, f := range fields {
+		values[f.key] = f.def
+	}
+	return layer{
+		values: values,
+		source: func(field) string { return SourceDefault },
+	}
+}
+
+// envLayer returns the fields set in the environment. Each field may
+// instead be read from the file named by its variable with FileEnvSuffix,
+// so secrets need not be placed in the environment itself. Setting both
+// forms of a variable is an error.
+func envLayer() layer {
+	values := make(map[string]string)
+	fromFile := make(map[string]bool)
+	var errs []error
+	for _, f := range fields {
+		value := os.Getenv(f.env)
+		path := os.Getenv(f.env + FileEnvSuffix)
+		switch {
+		case path != "" && value != "":
+			errs = append(errs, &FieldError{
+				Field:  f.key,
+				Source: "env " + f.env,
+				Err:    fmt.Errorf("%s and %s%s are both set", f.env, f.env, FileEnvSuffix),
+			})
+		case path != "":
+			content, err := readValueFile(path)
+			if err != nil {
+				errs = append(errs, &FieldError{Field: f.key, Source: "env " + f.env + FileEnvSuffix, Err: err})
+				continue
+			}
+			values[f.key] = content
+			fromFile[f.key] = true
+		case value != "":
+			values[f.key] = value
+		}
+	}
+	return layer{
+		values: values,
+		source: func(f field) string {
+			if fromFile[f.key] {
+				return "env " + f.env + FileEnvSuffix
+			}
+			return "env " + f.env
+		},
+		errs: errs,
+	}
+}
+
+// readValueFile reads a setting from a file, dropping the trailing newline
+// most editors and secret stores add.
+func readValueFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("could not read value file: %w", err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// fileLayer reads a YAML, TOML or JSON config file, chosen by extension.
+// Keys are the snake_case field keys; lists may be given as arrays or
+// comma-separated strings. String values may reference environment
+// variables; see interpolate.
+func fileLayer(path string) (layer, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return layer{}, fmt.Errorf("could not read config file: %w", err)
+	}
+
+	raw := make(map[string]any)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &raw)
+	case ".toml":
+		err = toml.Unmarshal(content, &raw)
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		err = decoder.Decode(&raw)
+	default:
+		return layer{}, fmt.Errorf("unsupported config file format %q (must be .yaml, .yml, .toml, or .json)", ext)
+	}
+	if err != nil {
+		return layer{}, fmt.Errorf("could not parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+	var unknown []string
+	for key, value := range raw {
+		if _, ok := lookupField(key); !ok {
+			unknown = append(unknown, key)
+			continue
+		}
+		str, err := fileValueString(value, os.LookupEnv)
+		if err != nil {
+			return layer{}, fmt.Errorf("config file %s: %s: %w", path, key, err)
+		}
+		values[key] = str
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return layer{}, fmt.Errorf("config file %s: unknown keys: %s", path, strings.Join(unknown, ", "))
+	}
+
+	return layer{
+		values: values,
+		source: func(field) string { return "file " + path },
+	}, nil
+}
+
+// fileValueString converts a decoded config file value to the string form
+// accepted by field setters, interpolating variables in strings.
+func fileValueString(value any, lookup func(string) (string, bool)) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return interpolate(v, lookup)
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			str, err := fileValueString(item, lookup)
+			if err != nil {
+				return "", err
+			}
+			items[i] = str
+		}
+		return strings.Join(items, ","), nil
+	case map[string]any:
+		return "", fmt.Errorf("nested values are not supported")
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// interpolate replaces ${NAME} in s with the value of the variable NAME,
+// and ${NAME:-default} with default when NAME is unset or empty. "$$" is
+// a literal "$"; any other "$" is kept as is. Referencing an unset
+// variable without a default is an error.
+func interpolate(s string, lookup func(string) (string, bool)) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case '$':
+			b.WriteByte('$')
+			i++
+		case '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable reference in %q", s)
+			}
+			expr := s[i+2 : i+2+end]
+			name, def, hasDefault := strings.Cut(expr, ":-")
+			if !validVarName(name) {
+				return "", fmt.Errorf("invalid variable reference ${%s}", expr)
+			}
+			value, ok := lookup(name)
+			switch {
+			case value != "":
+				b.WriteString(value)
+			case hasDefault:
+				b.WriteString(def)
+			case !ok:
+				return "", fmt.Errorf("variable %s is not set", name)
+			}
+			i += end + 2
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String(), nil
+}
+
+// validVarName reports whether name is a valid environment variable name.
+func validVarName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// flagValue records whether and how a command-line flag was set.
+type flagValue struct {
+	value  string
+	isBool bool
+}
+
+// String implements flag.Value.
+func (v *flagValue) String() string {
+	return v.value
+}
+
+// Set implements flag.Value.
+func (v *flagValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+// IsBoolFlag lets boolean settings be given as a bare --flag.
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+// parseFlags parses command-line arguments with fs into a flag layer and
+// returns the config file path given with --config, if any. Flags already
+// defined on fs are parsed too but do not form part of the layer.
+func parseFlags(fs *flag.FlagSet, args []string) (layer, string, error) {
+	configPath := fs.String("config", "", "path to a YAML, TOML or JSON config file (env "+ConfigFileEnv+")")
+
+	flagValues := make(map[string]*flagValue, len(fields))
+	for _, f := range fields {
+		v := &flagValue{isBool: f.boolean}
+		flagValues[f.flagName()] = v
+		fs.Var(v, f.flagName(), f.usage+" (env "+f.env+")")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return layer{}, "", err
+	}
+	if fs.NArg() > 0 {
+		return layer{}, "", fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	values := make(map[string]string)
+	fs.Visit(func(fl *flag.Flag) {
+		if v, ok := flagValues[fl.Name]; ok {
+			values[strings.ReplaceAll(fl.Name, "-", "_")] = v.value
+		}
+	})
+
+	return layer{
+		values: values,
+		source: func(f field) string { return "flag --" + f.flagName() },
+	}, *configPath, nil
+}
@@ -0,0 +1,292 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfigFile writes content to a file with the given name in a temp dir.
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestLoadArgs_FileFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name: "yaml",
+			file: "config.yaml",
+			content: `port: 4000
+json_log: true
+read_timeout: 5s
+log_outputs: [stdout, "file:/tmp/api.log"]
+log_file_max_backups: 3
+`,
+		},
+		{
+			name: "toml",
+			file: "config.toml",
+			content: `port = "4000"
+json_log = true
+read_timeout = "5s"
+log_outputs = ["stdout", "file:/tmp/api.log"]
+log_file_max_backups = 3
+`,
+		},
+		{
+			name: "json",
+			file: "config.json",
+			content: `{"port": 4000, "json_log": true, "read_timeout": "5s",
+"log_outputs": "stdout,file:/tmp/api.log", "log_file_max_backups": 3}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfigFile(t, tt.file, tt.content)
+
+			cfg, err := LoadArgs([]string{"--config", path})
+			if err != nil {
+				t.Fatalf("LoadArgs() error = %v", err)
+			}
+			if cfg.Port != "4000" {
+				t.Errorf("Port = %v, want 4000", cfg.Port)
+			}
+			if !cfg.JSONLog {
+				t.Error("JSONLog = false, want true")
+			}
+			if cfg.ReadTimeout != 5*time.Second {
+				t.Errorf("ReadTimeout = %v, want 5s", cfg.ReadTimeout)
+			}
+			if len(cfg.LogOutputs) != 2 || cfg.LogOutputs[1] != "file:/tmp/api.log" {
+				t.Errorf("LogOutputs = %v", cfg.LogOutputs)
+			}
+			if cfg.LogFileMaxBackups != 3 {
+				t.Errorf("LogFileMaxBackups = %v, want 3", cfg.LogFileMaxBackups)
+			}
+			if got := cfg.Source("port"); got != "file "+path {
+				t.Errorf("Source(port) = %q, want file source", got)
+			}
+			if got := cfg.Source("write_timeout"); got != SourceDefault {
+				t.Errorf("Source(write_timeout) = %q, want %q", got, SourceDefault)
+			}
+		})
+	}
+}
+
+func TestLoadArgs_Precedence(t *testing.T) {
+	flagDataFile := writeConfigFile(t, "flag.json", "[]")
+	path := writeConfigFile(t, "config.yaml", "port: 4000\nlog_level: warn\ndata_file_path: ./file.json\n")
+	t.Setenv("LOG_LEVEL", "error")
+	t.Setenv("DATA_FILE_PATH", "./env.json")
+	t.Setenv(ConfigFileEnv, path)
+
+	cfg, err := LoadArgs([]string{"--data-file-path", flagDataFile, "--json-log"})
+	if err != nil {
+		t.Fatalf("LoadArgs() error = %v", err)
+	}
+
+	tests := []struct {
+		key, value, source string
+	}{
+		{key: "port", value: cfg.Port, source: "file " + path},
+		{key: "log_level", value: cfg.LogLevel, source: "env LOG_LEVEL"},
+		{key: "data_file_path", value: cfg.DataFilePath, source: "flag --data-file-path"},
+		{key: "json_log", source: "flag --json-log"},
+	}
+	want := map[string]string{"port": "4000", "log_level": "error", "data_file_path": flagDataFile}
+
+	for _, tt := range tests {
+		if w, ok := want[tt.key]; ok && tt.value != w {
+			t.Errorf("%s = %q, want %q", tt.key, tt.value, w)
+		}
+		if got := cfg.Source(tt.key); got != tt.source {
+			t.Errorf("Source(%s) = %q, want %q", tt.key, got, tt.source)
+		}
+	}
+	if !cfg.JSONLog {
+		t.Error("JSONLog = false, want true from bare --json-log flag")
+	}
+}
+
+func TestLoadArgs_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    func(t *testing.T) []string
+		wantErr string
+	}{
+		{
+			name: "unknown file key",
+			args: func(t *testing.T) []string {
+				return []string{"--config", writeConfigFile(t, "c.yaml", "prot: 4000\n")}
+			},
+			wantErr: "unknown keys: prot",
+		},
+		{
+			name: "unsupported extension",
+			args: func(t *testing.T) []string {
+				return []string{"--config", writeConfigFile(t, "c.ini", "port=4000\n")}
+			},
+			wantErr: "unsupported config file format",
+		},
+		{
+			name: "missing file",
+			args: func(_ *testing.T) []string {
+				return []string{"--config", "/nonexistent/config.yaml"}
+			},
+			wantErr: "could not read config file",
+		},
+		{
+			name: "nested value",
+			args: func(t *testing.T) []string {
+				return []string{"--config", writeConfigFile(t, "c.yaml", "port:\n  number: 4000\n")}
+			},
+			wantErr: "nested values are not supported",
+		},
+		{
+			name:    "unknown flag",
+			args:    func(_ *testing.T) []string { return []string{"--prot", "4000"} },
+			wantErr: "flag provided but not defined",
+		},
+		{
+			name:    "positional argument",
+			args:    func(_ *testing.T) []string { return []string{"serve"} },
+			wantErr: "unexpected arguments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadArgs(tt.args(t))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadArgs() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadArgs_EnvFile(t *testing.T) {
+	secret := writeConfigFile(t, "token", "s3cr3t\n")
+	t.Setenv("ADMIN_TOKEN_FILE", secret)
+
+	cfg, err := LoadArgs(nil)
+	if err != nil {
+		t.Fatalf("LoadArgs() error = %v", err)
+	}
+	if cfg.AdminToken != "s3cr3t" {
+		t.Errorf("AdminToken = %q, want %q", cfg.AdminToken, "s3cr3t")
+	}
+	if got := cfg.Source("admin_token"); got != "env ADMIN_TOKEN_FILE" {
+		t.Errorf("Source(admin_token) = %q, want %q", got, "env ADMIN_TOKEN_FILE")
+	}
+
+	t.Setenv("ADMIN_TOKEN", "other")
+	if _, err := LoadArgs(nil); err == nil || !strings.Contains(err.Error(), "ADMIN_TOKEN and ADMIN_TOKEN_FILE are both set") {
+		t.Errorf("LoadArgs() error = %v, want conflict error", err)
+	}
+
+	t.Setenv("ADMIN_TOKEN", "")
+	t.Setenv("ADMIN_TOKEN_FILE", "/nonexistent/token")
+	if _, err := LoadArgs(nil); err == nil || !strings.Contains(err.Error(), "(from env ADMIN_TOKEN_FILE)") {
+		t.Errorf("LoadArgs() error = %v, want unreadable file error", err)
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	env := map[string]string{"HOST": "example.com", "EMPTY": ""}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{name: "plain", input: "no variables", want: "no variables"},
+		{name: "variable", input: "https://${HOST}/api", want: "https://example.com/api"},
+		{name: "default unset", input: "${PORT:-3000}", want: "3000"},
+		{name: "default empty", input: "${EMPTY:-x}", want: "x"},
+		{name: "set empty", input: "a${EMPTY}b", want: "ab"},
+		{name: "escaped", input: "price $$5 ${HOST}", want: "price $5 example.com"},
+		{name: "bare dollar", input: "$HOST$", want: "$HOST$"},
+		{name: "unset", input: "${MISSING}", wantErr: "variable MISSING is not set"},
+		{name: "unterminated", input: "${HOST", wantErr: "unterminated"},
+		{name: "invalid name", input: "${1X}", wantErr: "invalid variable reference"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := interpolate(tt.input, lookup)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("interpolate() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("interpolate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("interpolate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadArgs_FileInterpolation(t *testing.T) {
+	t.Setenv("TEST_LOG_LEVEL", "warn")
+	t.Setenv("TEST_ORIGIN", "https://app.example.com")
+	path := writeConfigFile(t, "config.yaml", "log_level: ${TEST_LOG_LEVEL}\ncors_allowed_origins: [\"${TEST_ORIGIN}\"]\nport: ${TEST_PORT:-4000}\n")
+
+	cfg, err := LoadArgs([]string{"--config", path})
+	if err != nil {
+		t.Fatalf("LoadArgs() error = %v", err)
+	}
+	if cfg.LogLevel != "warn" || cfg.Port != "4000" {
+		t.Errorf("LogLevel = %q, Port = %q, want warn and 4000", cfg.LogLevel, cfg.Port)
+	}
+	if len(cfg.CORSAllowedOrigins) != 1 || cfg.CORSAllowedOrigins[0] != "https://app.example.com" {
+		t.Errorf("CORSAllowedOrigins = %v", cfg.CORSAllowedOrigins)
+	}
+
+	path = writeConfigFile(t, "config.yaml", "admin_token: ${TEST_UNSET_TOKEN}\n")
+	if _, err := LoadArgs([]string{"--config", path}); err == nil || !strings.Contains(err.Error(), "admin_token: variable TEST_UNSET_TOKEN is not set") {
+		t.Errorf("LoadArgs() error = %v, want unset variable error", err)
+	}
+}
+
+func TestValidate_ReportsSource(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "log_level: verbose\n")
+	t.Setenv("ACCESS_LOG_FORMAT", "common")
+
+	_, err := LoadArgs([]string{"--config", path})
+	if err == nil {
+		t.Fatal("LoadArgs() expected error")
+	}
+
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) {
+		t.Fatalf("LoadArgs() error = %v, want a *FieldError", err)
+	}
+	if !strings.Contains(err.Error(), "log_level: invalid log level: verbose") || !strings.Contains(err.Error(), "(from file "+path+")") {
+		t.Errorf("error = %v, want log_level reported with its file source", err)
+	}
+	if !strings.Contains(err.Error(), "(from env ACCESS_LOG_FORMAT)") {
+		t.Errorf("error = %v, want access_log_format reported with its env source", err)
+	}
+}
@@ -0,0 +1,125 @@
+// Package filter parses and evaluates filter expressions such as
+//
+//	conference eq 'Pac-12 Conference' and not ncaa eq 'Division II'
+//
+// An expression compares fields with quoted strings and combines the
+// comparisons with and, or, not and parentheses. Keywords are
+// case-insensitive; field names and values are not.
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op is a comparison operator.
+type Op string
+
+// Comparison operators. Ordering operators compare strings bytewise.
+const (
+	Eq         Op = "eq"
+	Ne         Op = "ne"
+	Lt         Op = "lt"
+	Le         Op = "le"
+	Gt         Op = "gt"
+	Ge         Op = "ge"
+	Contains   Op = "contains"
+	StartsWith Op = "startswith"
+	EndsWith   Op = "endswith"
+)
+
+// Ops returns every comparison operator.
+func Ops() []Op {
+	return []Op{Eq, Ne, Lt, Le, Gt, Ge, Contains, StartsWith, EndsWith}
+}
+
+// compare reports whether value op operand holds.
+func (op Op) compare(value, operand string) bool {
+	switch op {
+	case Eq:
+		return value == operand
+	case Ne:
+		return value != operand
+	case Lt:
+		return value < operand
+	case Le:
+		return value <= operand
+	case Gt:
+		return value > operand
+	case Ge:
+		return value >= operand
+	case Contains:
+		return strings.Contains(value, operand)
+	case StartsWith:
+		return strings.HasPrefix(value, operand)
+	case EndsWith:
+		return strings.HasSuffix(value, operand)
+	default:
+		return false
+	}
+}
+
+// Record returns the value of a field of the record being matched.
+type Record func(field string) string
+
+// Expr is a node of a parsed expression.
+type Expr interface {
+	// Pos is the 1-based character position of the node in the source.
+	Pos() int
+	// Match reports whether the record satisfies the expression.
+	Match(r Record) bool
+	// String returns the expression in canonical form, fully
+	// parenthesized.
+	String() string
+}
+
+// Compare is a comparison of a field with a value.
+type Compare struct {
+	Field    string
+	Op       Op
+	Value    string
+	Position int
+}
+
+// Not negates an expression.
+type Not struct {
+	X        Expr
+	Position int
+}
+
+// Logical combines two expressions with and or or.
+type Logical struct {
+	// Op is "and" or "or".
+	Op          string
+	Left, Right Expr
+	Position    int
+}
+
+func (e *Compare) Pos() int { return e.Position }
+func (e *Not) Pos() int     { return e.Position }
+func (e *Logical) Pos() int { return e.Position }
+
+func (e *Compare) Match(r Record) bool { return e.Op.compare(r(e.Field), e.Value) }
+func (e *Not) Match(r Record) bool     { return !e.X.Match(r) }
+
+func (e *Logical) Match(r Record) bool {
+	if e.Op == "and" {
+		return e.Left.Match(r) && e.Right.Match(r)
+	}
+	return e.Left.Match(r) || e.Right.Match(r)
+}
+
+func (e *Compare) String() string {
+	return fmt.Sprintf("%s %s %s", e.Field, e.Op, quote(e.Value))
+}
+
+func (e *Not) String() string { return "not " + e.X.String() }
+
+func (e *Logical) String() string {
+	return fmt.Sprintf("(%s %s %s)", e.Left, e.Op, e.Right)
+}
+
+// quote returns s as a string literal.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
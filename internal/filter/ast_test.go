@@ -0,0 +1,40 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	record := map[string]string{"school": "Iowa State University", "ncaa": "Division I", "conference": "Big 12 Conference"}
+	get := func(field string) string { return record[field] }
+
+	tests := []struct {
+		src      string
+		expected bool
+	}{
+		{src: "school eq 'Iowa State University'", expected: true},
+		{src: "school eq 'iowa state university'", expected: false},
+		{src: "school ne 'Iowa State University'", expected: false},
+		{src: "school lt 'J'", expected: true},
+		{src: "school le 'Iowa State University'", expected: true},
+		{src: "school gt 'J'", expected: false},
+		{src: "school ge 'Iowa'", expected: true},
+		{src: "school contains 'State'", expected: true},
+		{src: "school startswith 'Iowa'", expected: true},
+		{src: "school endswith 'College'", expected: false},
+		{src: "conference eq 'Big 12 Conference' and not ncaa eq 'Division II'", expected: true},
+		{src: "conference eq 'SEC' or ncaa eq 'Division I'", expected: true},
+		{src: "not (conference eq 'SEC' or ncaa eq 'Division I')", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			expr, err := Parse(tt.src, fields)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, expr.Match(get))
+		})
+	}
+}
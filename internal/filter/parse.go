@@ -0,0 +1,262 @@
+package filter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// MaxDepth is how deeply parentheses and not may nest.
+const MaxDepth = 32
+
+// Error is a syntax or validation error in an expression.
+type Error struct {
+	// Pos is the 1-based character position of the error in the source.
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("at position %d: %s", e.Pos, e.Msg)
+}
+
+// Parse parses src, whose fields must be among fields. Errors are *Error.
+//
+// The grammar, with not binding tighter than and, and and tighter than or:
+//
+//	expr       = term { "or" term }
+//	term       = factor { "and" factor }
+//	factor     = "not" factor | "(" expr ")" | comparison
+//	comparison = field op string
+//	op         = eq | ne | lt | le | gt | ge | contains | startswith | endswith
+//	string     = "'" { character | "''" } "'"
+func Parse(src string, fields []string) (Expr, error) {
+	p := &parser{fields: fields}
+	if err := p.lex(src); err != nil {
+		return nil, err
+	}
+	if p.peek().kind == tokEOF {
+		return nil, p.errorf(p.peek(), "filter is empty")
+	}
+
+	expr, err := p.expr(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "expected and, or or the end of the filter, found %s", t)
+	}
+	return expr, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokLParen
+	tokRParen
+)
+
+// token is a lexical token. Keywords are identifiers.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// keyword returns the lower-cased text of an identifier, for matching
+// keywords case-insensitively.
+func (t token) keyword() string {
+	if t.kind != tokIdent {
+		return ""
+	}
+	return strings.ToLower(t.text)
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "the end of the filter"
+	case tokString:
+		return "string " + quote(t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+type parser struct {
+	fields []string
+	tokens []token
+	next   int
+}
+
+// lex splits src into tokens, ending with tokEOF.
+func (p *parser) lex(src string) error {
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			p.tokens = append(p.tokens, token{kind: tokLParen, text: "(", pos: start})
+			i++
+		case r == ')':
+			p.tokens = append(p.tokens, token{kind: tokRParen, text: ")", pos: start})
+			i++
+		case r == '\'':
+			var sb strings.Builder
+			i++
+			for {
+				if i == len(runes) {
+					return &Error{Pos: start, Msg: "unterminated string"}
+				}
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						sb.WriteRune('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			p.tokens = append(p.tokens, token{kind: tokString, text: sb.String(), pos: start})
+		case isIdentStart(r):
+			j := i + 1
+			for j < len(runes) && isIdentPart(runes[j]) {
+				j++
+			}
+			p.tokens = append(p.tokens, token{kind: tokIdent, text: string(runes[i:j]), pos: start})
+			i = j
+		default:
+			return &Error{Pos: start, Msg: fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+	p.tokens = append(p.tokens, token{kind: tokEOF, pos: len(runes) + 1})
+	return nil
+}
+
+func isIdentStart(r rune) bool { return r == '_' || unicode.IsLetter(r) }
+func isIdentPart(r rune) bool  { return isIdentStart(r) || unicode.IsDigit(r) }
+
+func (p *parser) peek() token { return p.tokens[p.next] }
+
+func (p *parser) advance() token {
+	t := p.tokens[p.next]
+	if t.kind != tokEOF {
+		p.next++
+	}
+	return t
+}
+
+func (p *parser) errorf(t token, format string, args ...any) *Error {
+	return &Error{Pos: t.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// expr parses an or-expression at nesting depth.
+func (p *parser) expr(depth int) (Expr, error) {
+	left, err := p.term(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().keyword() == "or" {
+		op := p.advance()
+		right, err := p.term(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{Op: "or", Left: left, Right: right, Position: op.pos}
+	}
+	return left, nil
+}
+
+// term parses an and-expression.
+func (p *parser) term(depth int) (Expr, error) {
+	left, err := p.factor(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().keyword() == "and" {
+		op := p.advance()
+		right, err := p.factor(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{Op: "and", Left: left, Right: right, Position: op.pos}
+	}
+	return left, nil
+}
+
+// factor parses a negation, a parenthesized expression or a comparison.
+func (p *parser) factor(depth int) (Expr, error) {
+	t := p.peek()
+	if depth >= MaxDepth && (t.keyword() == "not" || t.kind == tokLParen) {
+		return nil, p.errorf(t, "filter is nested more than %d levels deep", MaxDepth)
+	}
+
+	switch {
+	case t.keyword() == "not":
+		p.advance()
+		x, err := p.factor(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &Not{X: x, Position: t.pos}, nil
+	case t.kind == tokLParen:
+		p.advance()
+		x, err := p.expr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.advance(); closing.kind != tokRParen {
+			return nil, p.errorf(closing, "expected ')' to close the '(' at position %d, found %s", t.pos, closing)
+		}
+		return x, nil
+	default:
+		return p.comparison()
+	}
+}
+
+// comparison parses field op string.
+func (p *parser) comparison() (Expr, error) {
+	field := p.advance()
+	if field.kind != tokIdent || isKeyword(field.keyword()) {
+		return nil, p.errorf(field, "expected a field name, found %s", field)
+	}
+	if !slices.Contains(p.fields, field.text) {
+		return nil, p.errorf(field, "unknown field %q (must be one of %s)", field.text, strings.Join(p.fields, ", "))
+	}
+
+	opTok := p.advance()
+	op := Op(opTok.keyword())
+	if !slices.Contains(Ops(), op) {
+		return nil, p.errorf(opTok, "expected an operator (%s), found %s", opNames(), opTok)
+	}
+
+	value := p.advance()
+	if value.kind != tokString {
+		return nil, p.errorf(value, "expected a quoted string, found %s", value)
+	}
+	return &Compare{Field: field.text, Op: op, Value: value.text, Position: field.pos}, nil
+}
+
+// isKeyword reports whether a lower-cased identifier is reserved.
+func isKeyword(s string) bool {
+	return s == "and" || s == "or" || s == "not" || slices.Contains(Ops(), Op(s))
+}
+
+// opNames lists the comparison operators.
+func opNames() string {
+	names := make([]string, len(Ops()))
+	for i, op := range Ops() {
+		names[i] = string(op)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,93 @@
+package filter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fields = []string{"school", "ncaa", "conference"}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{src: "school eq 'Iowa'", expected: "school eq 'Iowa'"},
+		{src: "conference EQ 'Pac-12 Conference' AND NOT ncaa eq 'Division II'", expected: "(conference eq 'Pac-12 Conference' and not ncaa eq 'Division II')"},
+		{src: "school eq 'a' or school eq 'b' and ncaa eq 'c'", expected: "(school eq 'a' or (school eq 'b' and ncaa eq 'c'))"},
+		{src: "(school eq 'a' or school eq 'b') and ncaa eq 'c'", expected: "((school eq 'a' or school eq 'b') and ncaa eq 'c')"},
+		{src: "not (school startswith 'Univ' or school endswith 'College')", expected: "not (school startswith 'Univ' or school endswith 'College')"},
+		{src: "school contains 'A&M' and school ge 'T'", expected: "(school contains 'A&M' and school ge 'T')"},
+		{src: "school eq 'Saint Mary''s'", expected: "school eq 'Saint Mary''s'"},
+		{src: "  school  ne  ''  ", expected: "school ne ''"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			expr, err := Parse(tt.src, fields)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, expr.String())
+
+			// The canonical form parses to the same expression.
+			again, err := Parse(expr.String(), fields)
+			require.NoError(t, err)
+			assert.Equal(t, expr.String(), again.String())
+		})
+	}
+}
+
+func TestParse_Positions(t *testing.T) {
+	expr, err := Parse("school eq 'a' and not ncaa eq 'b'", fields)
+	require.NoError(t, err)
+
+	and := expr.(*Logical)
+	assert.Equal(t, 15, and.Pos())
+	assert.Equal(t, 1, and.Left.Pos())
+	not := and.Right.(*Not)
+	assert.Equal(t, 19, not.Pos())
+	assert.Equal(t, &Compare{Field: "ncaa", Op: Eq, Value: "b", Position: 23}, not.X)
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		src string
+		pos int
+		msg string
+	}{
+		{src: "", pos: 1, msg: "filter is empty"},
+		{src: "   ", pos: 4, msg: "filter is empty"},
+		{src: "school eq 'Iowa", pos: 11, msg: "unterminated string"},
+		{src: "school = 'Iowa'", pos: 8, msg: `unexpected character '='`},
+		{src: "coach eq 'x'", pos: 1, msg: `unknown field "coach" (must be one of school, ncaa, conference)`},
+		{src: "school is 'x'", pos: 8, msg: `expected an operator (eq, ne, lt, le, gt, ge, contains, startswith, endswith), found "is"`},
+		{src: "school eq Iowa", pos: 11, msg: `expected a quoted string, found "Iowa"`},
+		{src: "school eq", pos: 10, msg: "expected a quoted string, found the end of the filter"},
+		{src: "school eq 'a' and", pos: 18, msg: "expected a field name, found the end of the filter"},
+		{src: "school eq 'a' ncaa eq 'b'", pos: 15, msg: `expected and, or or the end of the filter, found "ncaa"`},
+		{src: "(school eq 'a'", pos: 15, msg: "expected ')' to close the '(' at position 1, found the end of the filter"},
+		{src: "school eq 'a')", pos: 14, msg: `expected and, or or the end of the filter, found ")"`},
+		{src: "and eq 'a'", pos: 1, msg: `expected a field name, found "and"`},
+		{src: "'a' eq school", pos: 1, msg: "expected a field name, found string 'a'"},
+		{src: "école eq 'a'", pos: 1, msg: `unknown field "école"`},
+		{src: "school eq 'é' and é", pos: 19, msg: `unknown field "é"`},
+		{src: strings.Repeat("not ", MaxDepth+1) + "school eq 'a'", pos: 4*MaxDepth + 1, msg: "nested more than 32 levels deep"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Parse(tt.src, fields)
+			var ferr *Error
+			require.True(t, errors.As(err, &ferr), "error %v is not an *Error", err)
+			assert.Equal(t, tt.pos, ferr.Pos)
+			assert.Contains(t, ferr.Msg, tt.msg)
+		})
+	}
+}
+
+func TestError(t *testing.T) {
+	assert.Equal(t, "at position 3: oops", (&Error{Pos: 3, Msg: "oops"}).Error())
+}
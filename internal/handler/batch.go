@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+
+	"api/internal/model"
+	"api/internal/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxBatchSize is the most GUIDs a batch lookup accepts.
+const MaxBatchSize = 100
+
+// batchRequest is the request body accepted by GetDataByIDs.
+type batchRequest struct {
+	GUIDs []string `json:"guids" doc:"GUIDs to look up"`
+}
+
+// batchError reports a GUID of a batch lookup that has no entry.
+type batchError struct {
+	GUID string       `json:"guid" doc:"The GUID as given"`
+	Code problem.Code `json:"code" doc:"invalid_guid if the GUID is malformed, not_found if no entry has it"`
+}
+
+// batchResponse is the response of GetDataByIDs.
+type batchResponse struct {
+	Data   []model.Data `json:"data" doc:"Entries found, in the order their GUIDs were given"`
+	Errors []batchError `json:"errors" doc:"GUIDs without an entry, in the order they were given"`
+}
+
+// GetDataByIDs handles POST /v1/schools/batch requests to look up several
+// GUIDs at once. Each GUID is either found or reported in errors, so one
+// bad GUID does not fail the batch; duplicates are looked up once. The
+// number of GUIDs is checked by middleware.ValidateRequests against Spec.
+func (h *Handler) GetDataByIDs(c *gin.Context) {
+	var req batchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Write(c, problem.New(problem.CodeInvalidBody, err.Error()))
+		return
+	}
+
+	guids := make([]string, 0, len(req.GUIDs))
+	for _, guid := range req.GUIDs {
+		if !slices.Contains(guids, guid) {
+			guids = append(guids, guid)
+		}
+	}
+
+	valid := slices.DeleteFunc(slices.Clone(guids), func(guid string) bool { return !model.ValidateGUID(guid) })
+	found := h.service.GetDataByGUIDs(c.Request.Context(), valid)
+
+	resp := batchResponse{Data: []model.Data{}, Errors: []batchError{}}
+	for _, guid := range guids {
+		switch data, ok := found[guid]; {
+		case ok:
+			resp.Data = append(resp.Data, data)
+		case !model.ValidateGUID(guid):
+			resp.Errors = append(resp.Errors, batchError{GUID: guid, Code: problem.CodeInvalidGUID})
+		default:
+			resp.Errors = append(resp.Errors, batchError{GUID: guid, Code: problem.CodeNotFound})
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"api/internal/model"
+	"api/internal/problem"
+)
+
+func TestGetDataByIDs(t *testing.T) {
+	router, _ := setupTestRouter()
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/v1/schools/batch", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("found, missing and invalid", func(t *testing.T) {
+		missing := "00000000-0000-0000-0000-000000000000"
+		w := post(fmt.Sprintf(`{"guids":[%q,"not-a-guid",%q,%q]}`, missing, testGUID, missing))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var result batchResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		assert.Equal(t, []model.Data{testData[0]}, result.Data)
+		assert.Equal(t, []batchError{
+			{GUID: missing, Code: problem.CodeNotFound},
+			{GUID: "not-a-guid", Code: problem.CodeInvalidGUID},
+		}, result.Errors)
+	})
+
+	t.Run("all found", func(t *testing.T) {
+		w := post(fmt.Sprintf(`{"guids":[%q]}`, testGUID))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, fmt.Sprintf(`{"data":[%s],"errors":[]}`, mustJSON(t, testData[0])), w.Body.String())
+	})
+
+	tests := []struct {
+		name string
+		body string
+		code problem.Code
+	}{
+		{name: "empty", body: `{"guids":[]}`, code: problem.CodeInvalidRequest},
+		{name: "missing guids", body: `{}`, code: problem.CodeInvalidRequest},
+		{name: "too many", body: `{"guids":[` + strings.Repeat(`"x",`, MaxBatchSize) + `"x"]}`, code: problem.CodeInvalidRequest},
+		{name: "not a string", body: `{"guids":[1]}`, code: problem.CodeInvalidRequest},
+		{name: "malformed", body: `{"guids":`, code: problem.CodeInvalidBody},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := post(tt.body)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var p problem.Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+			assert.Equal(t, tt.code, p.Code)
+		})
+	}
+
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(b)
+}
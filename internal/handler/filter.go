@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"api/internal/filter"
+	"api/internal/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FilterParam is the query parameter holding a filter expression.
+const FilterParam = "filter"
+
+// MaxFilterLength is the longest filter expression accepted, in
+// characters.
+const MaxFilterLength = 2048
+
+// parseFilter returns the expression given in ?filter=, or nil if there is
+// none. If it is invalid it writes an invalid_request problem locating the
+// error and returns false.
+func parseFilter(c *gin.Context) (filter.Expr, bool) {
+	src := c.Query(FilterParam)
+	if src == "" {
+		return nil, true
+	}
+
+	expr, err := filter.Parse(src, schoolFields)
+	if err != nil {
+		fe := problem.FieldError{Field: FilterParam, In: "query", Message: err.Error()}
+		var ferr *filter.Error
+		if errors.As(err, &ferr) {
+			fe.Message, fe.Position = ferr.Msg, ferr.Pos
+		}
+		p := problem.New(problem.CodeInvalidRequest, fmt.Sprintf("query parameter %s %s", FilterParam, err))
+		p.Errors = []problem.FieldError{fe}
+		problem.Write(c, p)
+		return nil, false
+	}
+	return expr, true
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"api/internal/model"
+	"api/internal/problem"
+)
+
+func TestGetAllData_Filter(t *testing.T) {
+	router := setupStatsRouter()
+	get := func(query url.Values) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/v1/schools?"+query.Encode(), nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	guids := func(w *httptest.ResponseRecorder) []string {
+		var result []model.Data
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		var guids []string
+		for _, d := range result {
+			guids = append(guids, d.GUID[:1])
+		}
+		return guids
+	}
+
+	t.Run("match", func(t *testing.T) {
+		w := get(url.Values{"filter": {"conference eq 'Big Ten Conference' and not school contains 'Nebraska'"}})
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"2"}, guids(w))
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		w := get(url.Values{"filter": {"ncaa eq 'Division II'"}})
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+	})
+
+	t.Run("facets count the filtered records", func(t *testing.T) {
+		w := get(url.Values{"filter": {"location endswith 'IA, USA'"}, "group_by": {"conference"}})
+		require.Equal(t, http.StatusOK, w.Code)
+		var result facetedList
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		assert.Equal(t, 2, result.Total)
+		assert.Len(t, result.Facets[0].Counts, 2)
+	})
+
+	tests := []struct {
+		name     string
+		filter   string
+		expected problem.FieldError
+	}{
+		{
+			name:     "syntax error",
+			filter:   "conference eq 'Pac-12 Conference' and ncaa 'Division I'",
+			expected: problem.FieldError{Field: "filter", In: "query", Position: 44, Message: `expected an operator (eq, ne, lt, le, gt, ge, contains, startswith, endswith), found string 'Division I'`},
+		},
+		{
+			name:     "unknown field",
+			filter:   "division eq 'I'",
+			expected: problem.FieldError{Field: "filter", In: "query", Position: 1, Message: `unknown field "division" (must be one of guid, school, mascot, nickname, location, latlong, ncaa, conference)`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(url.Values{"filter": {tt.filter}})
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			var p problem.Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+			assert.Equal(t, problem.CodeInvalidRequest, p.Code)
+			assert.Equal(t, []problem.FieldError{tt.expected}, p.Errors)
+			assert.Contains(t, p.Detail, "query parameter filter at position")
+		})
+	}
+
+	t.Run("too long", func(t *testing.T) {
+		w := get(url.Values{"filter": {strings.Repeat("x", MaxFilterLength+1)}})
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "must be at most 2048 characters")
+	})
+}
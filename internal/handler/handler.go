@@ -2,10 +2,14 @@
 package handler
 
 import (
+	"iter"
 	"net/http"
 
+	"api/internal/config"
 	"api/internal/model"
+	"api/internal/problem"
 	"api/internal/service"
+	"api/internal/version"
 	"api/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -14,47 +18,77 @@ import (
 // Handler holds dependencies for HTTP handlers.
 type Handler struct {
 	service service.DataService
+	config  func() *config.Config
+}
+
+// Option configures optional handler dependencies.
+type Option func(*Handler)
+
+// WithConfig sets the configuration reported by the version endpoint.
+func WithConfig(cfg *config.Config) Option {
+	return WithConfigFunc(func() *config.Config { return cfg })
+}
+
+// WithConfigFunc sets a function returning the live configuration reported
+// by the version endpoint, for configurations that are reloaded at runtime.
+func WithConfigFunc(fn func() *config.Config) Option {
+	return func(h *Handler) {
+		h.config = fn
+	}
 }
 
 // NewHandler creates a new handler instance.
-func NewHandler(svc service.DataService) *Handler {
-	return &Handler{
+func NewHandler(svc service.DataService, opts ...Option) *Handler {
+	h := &Handler{
 		service: svc,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
-// GetAllData handles GET / requests to return all data.
+// GetAllData handles GET /v1/schools requests to return all data in the
+// format negotiated from ?format= and Accept. Records are streamed from a
+// snapshot of the data rather than copied and buffered. ?filter= restricts
+// the records to those matching an expression. With ?group_by= the records
+// are returned together with their counts by each dimension.
 func (h *Handler) GetAllData(c *gin.Context) {
-	data := h.service.GetAllData()
-	c.JSON(http.StatusOK, data)
+	dims, ok := groupBy(c)
+	if !ok {
+		return
+	}
+	expr, ok := parseFilter(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	var records iter.Seq[model.Data]
+	if expr != nil {
+		records = h.service.Filter(ctx, expr)
+	} else {
+		records = h.service.All(ctx)
+	}
+	if len(dims) > 0 {
+		respondFaceted(c, records, dims)
+		return
+	}
+	respondStream(c, records)
 }
 
-// GetDataByID handles GET /:guid requests to return data by GUID.
+// GetDataByID handles GET /v1/schools/:guid requests to return data by GUID.
+// The GUID format is checked by middleware.ValidateRequests against Spec.
 func (h *Handler) GetDataByID(c *gin.Context) {
 	guid := c.Param("guid")
 
-	// Validate GUID format
-	if !model.ValidateGUID(guid) {
-		requestID, _ := c.Get("request_id")
-		logger.Warn("Invalid GUID format", "guid", guid, "request_id", requestID)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":      "Invalid GUID format",
-			"request_id": requestID,
-		})
-		return
-	}
-
-	data := h.service.GetDataByGUID(guid)
+	data := h.service.GetDataByGUID(c.Request.Context(), guid)
 	if data == nil {
-		requestID, _ := c.Get("request_id")
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":      "Data not found",
-			"request_id": requestID,
-		})
+		problem.Write(c, problem.Newf(problem.CodeNotFound, "no school with GUID %s", guid))
 		return
 	}
 
-	c.JSON(http.StatusOK, data)
+	respond(c, data)
 }
 
 // HealthCheck handles GET /health requests for health checks.
@@ -63,3 +97,68 @@ func (h *Handler) HealthCheck(c *gin.Context) {
 		"status": "healthy",
 	})
 }
+
+// Version handles GET /version requests to return build and runtime information.
+func (h *Handler) Version(c *gin.Context) {
+	response := gin.H{
+		"build": version.Get(),
+	}
+	if h.config != nil {
+		response["config"] = h.config().Summary()
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// logLevelRequest is the request body accepted by SetLogLevel. Component
+// levels set to an empty string remove the override.
+type logLevelRequest struct {
+	Level      string            `json:"level,omitempty" doc:"Global log level (debug, info, warn, error)"`
+	Components map[string]string `json:"components,omitempty" doc:"Per-component level overrides"`
+}
+
+// GetLogLevel handles GET /admin/log-level requests to return the current log levels.
+func (h *Handler) GetLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"level":      logger.GetLevel(),
+		"components": logger.ComponentLevels(),
+	})
+}
+
+// SetLogLevel handles PUT /admin/log-level requests to change log levels at runtime.
+func (h *Handler) SetLogLevel(c *gin.Context) {
+	var req logLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Write(c, problem.New(problem.CodeInvalidBody, err.Error()))
+		return
+	}
+
+	// Validate everything before applying anything
+	if req.Level != "" && !logger.ValidLevel(req.Level) {
+		problem.Write(c, problem.Newf(problem.CodeInvalidLogLevel, "unknown level %q", req.Level))
+		return
+	}
+	for component, level := range req.Components {
+		if component == "" {
+			problem.Write(c, problem.New(problem.CodeInvalidLogLevel, "component name must not be empty"))
+			return
+		}
+		if level != "" && !logger.ValidLevel(level) {
+			problem.Write(c, problem.Newf(problem.CodeInvalidLogLevel, "unknown level %q for component %s", level, component))
+			return
+		}
+	}
+
+	if req.Level != "" {
+		_ = logger.SetLevel(req.Level)
+	}
+	for component, level := range req.Components {
+		_ = logger.SetComponentLevel(component, level)
+	}
+
+	logger.Component(c.Request.Context(), "handler").Warn("Log level changed",
+		"level", logger.GetLevel(),
+		"components", logger.ComponentLevels(),
+	)
+
+	h.GetLogLevel(c)
+}
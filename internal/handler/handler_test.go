@@ -1,17 +1,25 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"iter"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"api/internal/config"
+	"api/internal/filter"
+	"api/internal/middleware"
 	"api/internal/model"
 	"api/internal/service"
+	"api/pkg/logger"
 )
 
 var testGUID = "05024756-765e-41a9-89d7-1407436d9a58"
@@ -38,11 +46,21 @@ func setupTestRouter() (*gin.Engine, *Handler) {
 		data: testData,
 	}
 
-	h := NewHandler(svc)
+	cfg := &config.Config{
+		Port:         "3000",
+		DataFilePath: "./data.json",
+		LogLevel:     "info",
+	}
+
+	h := NewHandler(svc, WithConfig(cfg))
 	router := gin.New()
+	router.Use(middleware.ValidateRequests(Spec()))
 	router.GET("/health", h.HealthCheck)
-	router.GET("/", h.GetAllData)
-	router.GET("/:guid", h.GetDataByID)
+	router.GET("/version", h.Version)
+	router.GET("/admin/log-level", h.GetLogLevel)
+	router.PUT("/admin/log-level", h.SetLogLevel)
+	RegisterVersions(router, h.V1())
+	h.RegisterLegacyRoutes(router)
 
 	return router, h
 }
@@ -52,13 +70,11 @@ type mockService struct {
 	data []model.Data
 }
 
-func (m *mockService) GetAllData() []model.Data {
-	result := make([]model.Data, len(m.data))
-	copy(result, m.data)
-	return result
+func (m *mockService) All(_ context.Context) iter.Seq[model.Data] {
+	return slices.Values(m.data)
 }
 
-func (m *mockService) GetDataByGUID(guid string) *model.Data {
+func (m *mockService) GetDataByGUID(_ context.Context, guid string) *model.Data {
 	for i := range m.data {
 		if m.data[i].GUID == guid {
 			result := m.data[i]
@@ -68,6 +84,26 @@ func (m *mockService) GetDataByGUID(guid string) *model.Data {
 	return nil
 }
 
+func (m *mockService) GetDataByGUIDs(_ context.Context, guids []string) map[string]model.Data {
+	found := map[string]model.Data{}
+	for _, d := range m.data {
+		if slices.Contains(guids, d.GUID) {
+			found[d.GUID] = d
+		}
+	}
+	return found
+}
+
+func (m *mockService) Filter(_ context.Context, expr filter.Expr) iter.Seq[model.Data] {
+	return func(yield func(model.Data) bool) {
+		for _, d := range m.data {
+			if expr.Match(d.Field) && !yield(d) {
+				return
+			}
+		}
+	}
+}
+
 func TestHealthCheck(t *testing.T) {
 	router, _ := setupTestRouter()
 
@@ -105,6 +141,100 @@ func TestHealthCheck(t *testing.T) {
 	}
 }
 
+func TestVersion(t *testing.T) {
+	router, _ := setupTestRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/version", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var result struct {
+		Build struct {
+			Version   string `json:"version"`
+			GoVersion string `json:"go_version"`
+			Uptime    string `json:"uptime"`
+		} `json:"build"`
+		Config map[string]interface{} `json:"config"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &result)
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Build.Version)
+	assert.NotEmpty(t, result.Build.GoVersion)
+	assert.NotEmpty(t, result.Build.Uptime)
+	assert.Equal(t, "3000", result.Config["port"])
+}
+
+func TestLogLevel(t *testing.T) {
+	router, _ := setupTestRouter()
+	defer func() {
+		_ = logger.SetLevel("info")
+		logger.SetComponentLevels(nil)
+	}()
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		expectedStatus int
+		expectedLevel  string
+	}{
+		{
+			name:           "change global and component level",
+			method:         "PUT",
+			body:           `{"level":"debug","components":{"service":"warn"}}`,
+			expectedStatus: http.StatusOK,
+			expectedLevel:  "debug",
+		},
+		{
+			name:           "invalid level is rejected",
+			method:         "PUT",
+			body:           `{"level":"verbose"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid component level is rejected",
+			method:         "PUT",
+			body:           `{"level":"error","components":{"service":"loud"}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "malformed body is rejected",
+			method:         "PUT",
+			body:           `{"level":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "get current level",
+			method:         "GET",
+			expectedStatus: http.StatusOK,
+			expectedLevel:  "debug",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, "/admin/log-level", strings.NewReader(tt.body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedLevel == "" {
+				return
+			}
+
+			var result struct {
+				Level      string            `json:"level"`
+				Components map[string]string `json:"components"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+			assert.Equal(t, tt.expectedLevel, result.Level)
+			assert.Equal(t, "warn", result.Components["service"])
+		})
+	}
+}
+
 func TestGetAllData(t *testing.T) {
 	router, _ := setupTestRouter()
 
@@ -175,7 +305,7 @@ func TestGetDataByID(t *testing.T) {
 				var result map[string]interface{}
 				err := json.Unmarshal(body, &result)
 				require.NoError(t, err)
-				assert.Equal(t, "Invalid GUID format", result["error"])
+				assert.Equal(t, "invalid_guid", result["code"])
 			},
 		},
 		{
@@ -187,7 +317,7 @@ func TestGetDataByID(t *testing.T) {
 				var result map[string]interface{}
 				err := json.Unmarshal(body, &result)
 				require.NoError(t, err)
-				assert.Equal(t, "Data not found", result["error"])
+				assert.Equal(t, "not_found", result["code"])
 			},
 		},
 		{
@@ -199,7 +329,7 @@ func TestGetDataByID(t *testing.T) {
 				var result map[string]interface{}
 				err := json.Unmarshal(body, &result)
 				require.NoError(t, err)
-				assert.Equal(t, "Invalid GUID format", result["error"])
+				assert.Equal(t, "invalid_guid", result["code"])
 			},
 		},
 	}
@@ -0,0 +1,424 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"api/internal/filter"
+	"api/internal/model"
+	"api/internal/openapi"
+	"api/internal/problem"
+	"api/internal/render"
+	"api/internal/stats"
+	"api/internal/version"
+	"api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// specJSON is the encoded OpenAPI document, built on first use.
+var specJSON = sync.OnceValues(func() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(Spec()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+})
+
+// OpenAPI handles GET /openapi.json requests to return the OpenAPI document.
+func (h *Handler) OpenAPI(c *gin.Context) {
+	body, err := specJSON()
+	if err != nil {
+		problem.Write(c, problem.New(problem.CodeInternal, "could not encode the OpenAPI document"))
+		return
+	}
+	c.Data(http.StatusOK, "application/json", body)
+}
+
+// Spec returns the OpenAPI document describing every route served by a
+// Handler: the versioned API, the legacy aliases, and the health, version,
+// admin and OpenAPI routes registered by the server.
+func Spec() *openapi.Document {
+	doc := openapi.New(openapi.Info{
+		Title:       "Schools API",
+		Version:     version.Get().Version,
+		Description: "School and university data with mascots, locations and athletic conferences.",
+	})
+
+	doc.Components.Schemas["Data"] = dataSchema()
+	doc.Components.Schemas["Problem"] = problemSchema()
+	doc.Components.Schemas["Version"] = versionSchema()
+	doc.Components.Schemas["LogLevels"] = logLevelsSchema()
+	doc.Components.Schemas["Stats"] = statsSchema()
+	doc.Components.Schemas["FacetedSchools"] = facetedSchoolsSchema()
+	doc.Components.SecuritySchemes["adminToken"] = &openapi.SecurityScheme{
+		Type:        "http",
+		Scheme:      "bearer",
+		Description: "The ADMIN_TOKEN configured on the server; the admin routes are disabled while it is empty.",
+	}
+
+	doc.Add("GET", "/health", &openapi.Operation{
+		OperationID: "getHealth",
+		Summary:     "Health check",
+		Tags:        []string{"system"},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The service is healthy", &openapi.Schema{
+				Type:       "object",
+				Properties: map[string]*openapi.Schema{"status": {Type: "string", Enum: []any{"healthy"}}},
+				Required:   []string{"status"},
+			}),
+		},
+	})
+	doc.Add("GET", "/version", &openapi.Operation{
+		OperationID: "getVersion",
+		Summary:     "Build and runtime information",
+		Tags:        []string{"system"},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("Build information and the non-secret configuration", openapi.Ref("Version")),
+		},
+	})
+	doc.Add("GET", "/openapi.json", &openapi.Operation{
+		OperationID: "getOpenAPI",
+		Summary:     "This OpenAPI document",
+		Tags:        []string{"system"},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The OpenAPI document", &openapi.Schema{Type: "object"}),
+		},
+	})
+
+	admin := []map[string][]string{{"adminToken": {}}}
+	doc.Add("GET", "/admin/log-level", &openapi.Operation{
+		OperationID: "getLogLevel",
+		Summary:     "Current log levels",
+		Tags:        []string{"admin"},
+		Security:    admin,
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The global level and component overrides", openapi.Ref("LogLevels")),
+			"401": problemResponse("Missing or invalid admin token", problem.CodeUnauthorized),
+			"403": problemResponse("No admin token is configured", problem.CodeAdminDisabled),
+		},
+	})
+	doc.Add("PUT", "/admin/log-level", &openapi.Operation{
+		OperationID: "setLogLevel",
+		Summary:     "Change log levels",
+		Description: "Changes the global level and/or component overrides. An empty component level removes the override.",
+		Tags:        []string{"admin"},
+		Security:    admin,
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  map[string]*openapi.MediaType{"application/json": {Schema: openapi.Ref("LogLevels")}},
+		},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The levels after the change", openapi.Ref("LogLevels")),
+			"400": problemResponse("Malformed body or unknown level", problem.CodeInvalidBody, problem.CodeInvalidRequest, problem.CodeInvalidLogLevel),
+			"401": problemResponse("Missing or invalid admin token", problem.CodeUnauthorized),
+			"403": problemResponse("No admin token is configured", problem.CodeAdminDisabled),
+			"413": problemResponse("Body too large", problem.CodeBodyTooLarge),
+		},
+	})
+
+	addSchoolRoutes(doc, "/v1/schools", "", false)
+	addSchoolRoutes(doc, "", "Legacy", true)
+	doc.Add("POST", "/v1/schools/batch", &openapi.Operation{
+		OperationID: "batchGetSchools",
+		Summary:     "Get several schools by GUID",
+		Description: "Looks up to 100 GUIDs at once. GUIDs that are malformed or have no entry are listed in errors rather than failing the request.",
+		Tags:        []string{"schools"},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  map[string]*openapi.MediaType{"application/json": {Schema: batchRequestSchema()}},
+		},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The entries found and the GUIDs without one", batchResponseSchema()),
+			"400": problemResponse("Malformed body or too many GUIDs", problem.CodeInvalidBody, problem.CodeInvalidRequest),
+			"413": problemResponse("Body too large", problem.CodeBodyTooLarge),
+		},
+	})
+	doc.Add("GET", "/v1/stats", &openapi.Operation{
+		OperationID: "getStats",
+		Summary:     "School counts by conference, division, state and country",
+		Tags:        []string{"schools"},
+		Parameters:  []*openapi.Parameter{formatParameter(), groupByParameter("Comma-separated dimensions to count by; all dimensions by default")},
+		Responses: map[string]*openapi.Response{
+			"200": documentResponse("The number of schools and their counts by dimension", openapi.Ref("Stats")),
+			"400": problemResponse("Invalid parameter", problem.CodeInvalidRequest),
+			"406": problemResponse("None of the supported media types is acceptable", problem.CodeNotAcceptable),
+		},
+	})
+
+	// Any route can fail unexpectedly.
+	for _, r := range doc.Routes() {
+		doc.Operation(r.Method, r.Path).Responses["default"] = problemResponse("Unexpected error", problem.CodeInternal)
+	}
+	return doc
+}
+
+// addSchoolRoutes describes the list and GUID lookup routes under prefix.
+// Legacy routes are marked deprecated and get suffixed operation IDs.
+func addSchoolRoutes(doc *openapi.Document, prefix, suffix string, deprecated bool) {
+	listPath := prefix
+	if listPath == "" {
+		listPath = "/"
+	}
+
+	doc.Add("GET", listPath, &openapi.Operation{
+		OperationID: "listSchools" + suffix,
+		Summary:     "List all schools",
+		Description: "With group_by, the entries are returned in an object together with their counts by each dimension, in JSON, XML or YAML.",
+		Tags:        []string{"schools"},
+		Deprecated:  deprecated,
+		Parameters: []*openapi.Parameter{
+			formatParameter(),
+			fieldsParameter(),
+			groupByParameter("Comma-separated dimensions to count the entries by"),
+			filterParameter(),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": withDeprecation(listResponse(), deprecated),
+			"400": problemResponse("Invalid parameter", problem.CodeInvalidRequest),
+			"406": problemResponse("None of the supported media types is acceptable", problem.CodeNotAcceptable),
+		},
+	})
+	doc.Add("GET", prefix+"/:guid", &openapi.Operation{
+		OperationID: "getSchool" + suffix,
+		Summary:     "Get a school by GUID",
+		Tags:        []string{"schools"},
+		Deprecated:  deprecated,
+		Parameters: []*openapi.Parameter{{
+			Name:        "guid",
+			In:          "path",
+			Description: "GUID of the entry",
+			Required:    true,
+			Schema:      &openapi.Schema{Type: "string", Pattern: model.GUIDPattern},
+			ErrorCode:   string(problem.CodeInvalidGUID),
+		}, formatParameter(), fieldsParameter()},
+		Responses: map[string]*openapi.Response{
+			"200": withDeprecation(dataResponse("The entry", openapi.Ref("Data")), deprecated),
+			"400": problemResponse("Malformed GUID or invalid parameter", problem.CodeInvalidGUID, problem.CodeInvalidRequest),
+			"404": problemResponse("No entry has the GUID", problem.CodeNotFound),
+			"406": problemResponse("None of the supported media types is acceptable", problem.CodeNotAcceptable),
+		},
+	})
+}
+
+// jsonResponse describes a JSON response body.
+func jsonResponse(description string, schema *openapi.Schema) *openapi.Response {
+	return &openapi.Response{
+		Description: description,
+		Content:     map[string]*openapi.MediaType{"application/json": {Schema: schema}},
+	}
+}
+
+// formatParameter describes the ?format= override of the Accept header.
+func formatParameter() *openapi.Parameter {
+	var names []any
+	for _, f := range render.Formats() {
+		names = append(names, f.Name)
+	}
+	return &openapi.Parameter{
+		Name:        FormatParam,
+		In:          "query",
+		Description: "Response format, overriding the Accept header",
+		Schema:      &openapi.Schema{Type: "string", Enum: names},
+	}
+}
+
+// fieldsParameter describes the ?fields= selection of record fields.
+func fieldsParameter() *openapi.Parameter {
+	return listParameter(FieldsParam, "Comma-separated fields to include in each record, in any order; all fields by default", schoolFields)
+}
+
+// filterParameter describes the ?filter= expression.
+func filterParameter() *openapi.Parameter {
+	ops := make([]string, len(filter.Ops()))
+	for i, op := range filter.Ops() {
+		ops[i] = string(op)
+	}
+	maxLength := MaxFilterLength
+	return &openapi.Parameter{
+		Name: FilterParam,
+		In:   "query",
+		Description: "Filter expression, e.g. conference eq 'Pac-12 Conference' and not ncaa eq 'Division II'. " +
+			"Fields are compared with a quoted string using " + strings.Join(ops, ", ") +
+			", and comparisons are combined with and, or, not and parentheses.",
+		Schema: &openapi.Schema{Type: "string", MaxLength: &maxLength},
+	}
+}
+
+// groupByParameter describes the ?group_by= selection of dimensions.
+func groupByParameter(description string) *openapi.Parameter {
+	return listParameter(GroupByParam, description, stats.DimensionNames())
+}
+
+// listParameter describes a comma-separated list query parameter whose
+// items are one of values.
+func listParameter(name, description string, values []string) *openapi.Parameter {
+	enum := make([]any, len(values))
+	for i, value := range values {
+		enum[i] = value
+	}
+	explode := false
+	return &openapi.Parameter{
+		Name:        name,
+		In:          "query",
+		Description: description,
+		Schema:      &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: enum}},
+		Explode:     &explode,
+	}
+}
+
+// dataResponse describes a response available in every render format.
+// CSV has a header row with one column per Data property.
+func dataResponse(description string, schema *openapi.Schema) *openapi.Response {
+	content := map[string]*openapi.MediaType{}
+	for _, f := range render.Formats() {
+		switch f {
+		case render.CSV, render.NDJSON:
+			content[f.MediaType] = &openapi.MediaType{Schema: openapi.String()}
+		default:
+			content[f.MediaType] = &openapi.MediaType{Schema: schema}
+		}
+	}
+	return &openapi.Response{Description: description, Content: content}
+}
+
+// listResponse describes the list of entries, or with ?group_by= the
+// entries and their counts.
+func listResponse() *openapi.Response {
+	r := dataResponse("All entries", &openapi.Schema{Type: "array", Items: openapi.Ref("Data")})
+	for _, f := range documentFormats {
+		r.Content[f.MediaType].Schema = &openapi.Schema{OneOf: []*openapi.Schema{
+			r.Content[f.MediaType].Schema,
+			openapi.Ref("FacetedSchools"),
+		}}
+	}
+	return r
+}
+
+// documentResponse describes a response available in every document
+// format.
+func documentResponse(description string, schema *openapi.Schema) *openapi.Response {
+	content := map[string]*openapi.MediaType{}
+	for _, f := range documentFormats {
+		content[f.MediaType] = &openapi.MediaType{Schema: schema}
+	}
+	return &openapi.Response{Description: description, Content: content}
+}
+
+// problemResponse describes a problem details response with one of codes.
+func problemResponse(description string, codes ...problem.Code) *openapi.Response {
+	if len(codes) > 0 {
+		names := make([]string, len(codes))
+		for i, code := range codes {
+			names[i] = string(code)
+		}
+		description += " (code " + strings.Join(names, ", ") + ")"
+	}
+	return &openapi.Response{
+		Description: description,
+		Content:     map[string]*openapi.MediaType{problem.ContentType: {Schema: openapi.Ref("Problem")}},
+	}
+}
+
+// withDeprecation adds the headers set by middleware.Deprecated.
+func withDeprecation(r *openapi.Response, deprecated bool) *openapi.Response {
+	if !deprecated {
+		return r
+	}
+	r.Headers = map[string]*openapi.Header{
+		"Deprecation": {Description: "When the route was deprecated, as @<unix time>", Schema: openapi.String()},
+		"Sunset":      {Description: "When the route will be removed", Schema: openapi.String()},
+		"Link":        {Description: "The successor-version route", Schema: openapi.String()},
+	}
+	return r
+}
+
+// problemSchema returns the schema of problem.Problem with the known codes.
+func problemSchema() *openapi.Schema {
+	s := openapi.SchemaOf(problem.Problem{})
+	codes := problem.Codes()
+	enum := make([]any, len(codes))
+	for i, code := range codes {
+		enum[i] = string(code)
+	}
+	s.Properties["code"].Enum = enum
+	s.Properties["type"].Format = "uri"
+	return s
+}
+
+// logLevelsSchema returns the schema of the log level request and
+// response. An empty component level removes the override.
+func logLevelsSchema() *openapi.Schema {
+	s := openapi.SchemaOf(logLevelRequest{})
+	var levels, componentLevels []any
+	for _, name := range logger.LevelNames() {
+		levels = append(levels, name)
+		componentLevels = append(componentLevels, name)
+	}
+	s.Properties["level"].Enum = levels
+	s.Properties["components"].AdditionalProperties.Enum = append(componentLevels, "")
+	return s
+}
+
+// dataSchema returns the schema of model.Data as it appears in responses.
+// No property is required, since ?fields= leaves out those not selected,
+// and the GUID has no pattern, since entries are served as loaded.
+func dataSchema() *openapi.Schema {
+	s := openapi.SchemaOf(model.Data{})
+	s.Required = nil
+	return s
+}
+
+// batchRequestSchema returns the schema of the batch lookup request.
+func batchRequestSchema() *openapi.Schema {
+	s := openapi.SchemaOf(batchRequest{})
+	minItems, maxItems := 1, MaxBatchSize
+	s.Properties["guids"].MinItems = &minItems
+	s.Properties["guids"].MaxItems = &maxItems
+	return s
+}
+
+// batchResponseSchema returns the schema of the batch lookup response.
+func batchResponseSchema() *openapi.Schema {
+	s := openapi.SchemaOf(batchResponse{})
+	s.Properties["data"].Items = openapi.Ref("Data")
+	s.Properties["errors"].Items.Properties["code"].Enum = []any{string(problem.CodeInvalidGUID), string(problem.CodeNotFound)}
+	return s
+}
+
+// statsSchema returns the schema of stats.Stats with the dimension names.
+func statsSchema() *openapi.Schema {
+	s := openapi.SchemaOf(stats.Stats{})
+	names := make([]any, 0, len(stats.Dimensions()))
+	for _, dim := range stats.Dimensions() {
+		names = append(names, dim.Name)
+	}
+	s.Properties["facets"].Items.Properties["name"].Enum = names
+	return s
+}
+
+// facetedSchoolsSchema returns the schema of the list response with
+// ?group_by=.
+func facetedSchoolsSchema() *openapi.Schema {
+	s := openapi.SchemaOf(facetedList{})
+	s.Properties["data"].Items = openapi.Ref("Data")
+	s.Properties["facets"] = statsSchema().Properties["facets"]
+	return s
+}
+
+// versionSchema returns the schema of the Version response.
+func versionSchema() *openapi.Schema {
+	return &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"build":  openapi.SchemaOf(version.Info{}),
+			"config": {Type: "object", Description: "Effective non-secret configuration"},
+		},
+		Required: []string{"build"},
+	}
+}
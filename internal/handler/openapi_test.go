@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"api/internal/model"
+	"api/internal/openapi"
+	"api/internal/problem"
+	"api/internal/service"
+	"api/internal/stats"
+)
+
+func TestOpenAPI(t *testing.T) {
+	router, h := setupTestRouter()
+	router.GET("/openapi.json", h.OpenAPI)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/openapi.json", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var doc struct {
+		OpenAPI string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, "3.1.0", doc.OpenAPI)
+	assert.Contains(t, doc.Paths["/v1/schools/{guid}"], "get")
+}
+
+func TestSpec_DataSchema(t *testing.T) {
+	schema := Spec().Components.Schemas["Data"]
+	require.NotNil(t, schema)
+
+	// Every JSON field of model.Data is described, and only those.
+	typ := reflect.TypeOf(model.Data{})
+	var fields []string
+	for i := range typ.NumField() {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		fields = append(fields, name)
+		assert.NotEmpty(t, schema.Properties[name].Description, name)
+	}
+	assert.Len(t, schema.Properties, len(fields))
+	assert.Empty(t, schema.Required)
+	assert.Empty(t, schema.Properties["guid"].Pattern)
+
+	// The GUID path parameter is still checked.
+	for _, p := range Spec().Operation("GET", "/v1/schools/:guid").Parameters {
+		if p.Name == "guid" {
+			assert.Equal(t, model.GUIDPattern, p.Schema.Pattern)
+			assert.Equal(t, string(problem.CodeInvalidGUID), p.ErrorCode)
+		}
+	}
+}
+
+// TestSpec_Responses validates responses served from data.json against
+// the schemas the document declares for them.
+func TestSpec_Responses(t *testing.T) {
+	svc, err := service.NewService("./data.json")
+	require.NoError(t, err)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewHandler(svc)
+	RegisterVersions(router, h.V1())
+	h.RegisterLegacyRoutes(router)
+	doc := Spec()
+
+	tests := []struct {
+		method, route, path, body string
+	}{
+		{method: "GET", route: "/v1/schools", path: "/v1/schools"},
+		{method: "GET", route: "/v1/schools", path: "/v1/schools?fields=school,mascot"},
+		{method: "GET", route: "/v1/schools", path: "/v1/schools?group_by=conference&fields=guid"},
+		{method: "GET", route: "/v1/schools/:guid", path: "/v1/schools/" + testGUID},
+		{method: "GET", route: "/v1/schools/:guid", path: "/v1/schools/" + testGUID + "?fields=nickname"},
+		{method: "POST", route: "/v1/schools/batch", path: "/v1/schools/batch", body: `{"guids":["` + testGUID + `","5d67d6e7-7e7f-7g7g-d666-9d999g9d0a92","nope"]}`},
+		{method: "GET", route: "/v1/stats", path: "/v1/stats?group_by=ncaa"},
+		{method: "GET", route: "/", path: "/?fields=guid,school"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			op := doc.Operation(tt.method, tt.route)
+			require.NotNil(t, op)
+			schema := op.Responses["200"].Content["application/json"].Schema
+
+			var body any
+			dec := json.NewDecoder(w.Body)
+			dec.UseNumber()
+			require.NoError(t, dec.Decode(&body))
+			assert.Empty(t, doc.Validate(schema, body, ""))
+		})
+	}
+}
+
+func TestSpec_Problems(t *testing.T) {
+	doc := Spec()
+
+	schema := doc.Components.Schemas["Problem"]
+	require.NotNil(t, schema)
+	assert.Len(t, schema.Properties["code"].Enum, len(problem.Codes()))
+
+	for _, r := range doc.Routes() {
+		op := doc.Operation(r.Method, r.Path)
+		for status, resp := range op.Responses {
+			if status == "default" || strings.HasPrefix(status, "4") || strings.HasPrefix(status, "5") {
+				assert.Contains(t, resp.Content, problem.ContentType, "%s %s %s", r.Method, r.Path, status)
+			}
+		}
+	}
+}
+
+func TestSpec_Stats(t *testing.T) {
+	doc := Spec()
+
+	schema := doc.Components.Schemas["Stats"]
+	require.NotNil(t, schema)
+	assert.Len(t, schema.Properties["facets"].Items.Properties["name"].Enum, len(stats.DimensionNames()))
+
+	// Faceted lists are documents, which CSV and NDJSON cannot represent.
+	content := doc.Operation("GET", "/v1/schools").Responses["200"].Content
+	assert.Contains(t, content["application/json"].Schema.OneOf, openapi.Ref("FacetedSchools"))
+	assert.Empty(t, content["text/csv"].Schema.OneOf)
+	assert.NotContains(t, doc.Operation("GET", "/v1/stats").Responses["200"].Content, "text/csv")
+}
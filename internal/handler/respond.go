@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"iter"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"api/internal/model"
+	"api/internal/problem"
+	"api/internal/render"
+	"api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FormatParam is the query parameter overriding the Accept header.
+const FormatParam = "format"
+
+// FieldsParam is the query parameter selecting the fields of records.
+const FieldsParam = "fields"
+
+// schoolNames are the XML element names of school records.
+var schoolNames = render.Options{Item: "school", List: "schools"}
+
+// schoolFields are the fields of school records selectable by ?fields=.
+var schoolFields = render.FieldNames(reflect.TypeFor[model.Data]())
+
+// renderOptions returns the options for encoding school records, with the
+// fields selected by ?fields=. If a field is unknown it writes an
+// invalid_request problem and returns false.
+func renderOptions(c *gin.Context) (render.Options, bool) {
+	fields, ok := listParam(c, FieldsParam, schoolFields)
+	if !ok {
+		return render.Options{}, false
+	}
+	opts := schoolNames
+	opts.Fields = fields
+	return opts, true
+}
+
+// listParam returns the values of the comma-separated list query parameter
+// name, ignoring duplicates and empty values. If a value is not in allowed
+// it writes an invalid_request problem and returns false.
+func listParam(c *gin.Context, name string, allowed []string) ([]string, bool) {
+	var values []string
+	for value := range strings.SplitSeq(c.Query(name), ",") {
+		if value == "" || slices.Contains(values, value) {
+			continue
+		}
+		if !slices.Contains(allowed, value) {
+			problem.Write(c, problem.Newf(problem.CodeInvalidRequest, "unknown value %q in %s (must be one of %s)",
+				value, name, strings.Join(allowed, ", ")))
+			return nil, false
+		}
+		values = append(values, value)
+	}
+	return values, true
+}
+
+// documentFormats are the formats of responses that are a single document
+// rather than a list of records, which CSV and NDJSON cannot represent.
+var documentFormats = []render.Format{render.JSON, render.XML, render.YAML}
+
+// negotiateFormat returns the format in offers requested by ?format=, or
+// else by the Accept header.
+func negotiateFormat(c *gin.Context, offers []render.Format) (render.Format, bool) {
+	if name := c.Query(FormatParam); name != "" {
+		f, ok := render.ByName(name)
+		return f, ok && slices.Contains(offers, f)
+	}
+	return render.Negotiate(c.GetHeader("Accept"), offers)
+}
+
+// startResponse negotiates the response format among offers and sends the
+// status and headers of a 200 response in it. If the client accepts none
+// of the offers it writes a not_acceptable problem and returns false.
+func startResponse(c *gin.Context, offers []render.Format) (render.Format, bool) {
+	c.Writer.Header().Add("Vary", "Accept")
+
+	f, ok := negotiateFormat(c, offers)
+	if !ok {
+		problem.Write(c, problem.Newf(problem.CodeNotAcceptable, "supported media types are %s", mediaTypes(offers)))
+		return render.Format{}, false
+	}
+
+	c.Header("Content-Type", f.ContentType())
+	c.Status(http.StatusOK)
+	return f, true
+}
+
+// respond writes data with status 200 in the negotiated format, restricted
+// to the fields selected by ?fields=.
+func respond(c *gin.Context, data any) {
+	opts, ok := renderOptions(c)
+	if !ok {
+		return
+	}
+	f, ok := startResponse(c, render.Formats())
+	if !ok {
+		return
+	}
+	if err := render.Encode(c.Writer, f, data, opts); err != nil {
+		// The status has been sent; all that is left is to log.
+		logger.Component(c.Request.Context(), "handler").Error("Could not encode response",
+			"format", f.Name,
+			"error", err,
+		)
+	}
+}
+
+// respondStream writes records with status 200 in the negotiated format as
+// they are yielded, flushing periodically so memory use stays bounded. If
+// the client goes away or the write deadline passes, the response is cut
+// short and the list left unterminated.
+func respondStream(c *gin.Context, records iter.Seq[model.Data]) {
+	opts, ok := renderOptions(c)
+	if !ok {
+		return
+	}
+	f, ok := startResponse(c, render.Formats())
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	n, err := render.Stream(ctx, c.Writer, f, records, opts, c.Writer.Flush)
+	if err != nil {
+		logger.Component(ctx, "handler").Warn("Response stream aborted",
+			"format", f.Name,
+			"records", n,
+			"error", err,
+		)
+	}
+}
+
+// respondDocument writes v with status 200 in the negotiated document
+// format. XML documents are a single element named opts.Item.
+func respondDocument(c *gin.Context, v any, opts render.Options) {
+	f, ok := startResponse(c, documentFormats)
+	if !ok {
+		return
+	}
+	if err := render.Encode(c.Writer, f, v, opts); err != nil {
+		logger.Component(c.Request.Context(), "handler").Error("Could not encode response",
+			"format", f.Name,
+			"error", err,
+		)
+	}
+}
+
+// mediaTypes lists the media types of formats.
+func mediaTypes(formats []render.Format) string {
+	types := make([]string, 0, len(formats))
+	for _, f := range formats {
+		types = append(types, f.MediaType)
+	}
+	return strings.Join(types, ", ")
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"api/internal/model"
+	"api/internal/problem"
+	"api/internal/render"
+)
+
+func TestContentNegotiation(t *testing.T) {
+	router, _ := setupTestRouter()
+
+	tests := []struct {
+		name                string
+		path                string
+		accept              string
+		expectedStatus      int
+		expectedContentType string
+	}{
+		{name: "default json", path: "/v1/schools", expectedStatus: 200, expectedContentType: "application/json; charset=utf-8"},
+		{name: "accept csv", path: "/v1/schools", accept: "text/csv", expectedStatus: 200, expectedContentType: "text/csv; charset=utf-8"},
+		{name: "accept ndjson", path: "/v1/schools", accept: "application/x-ndjson", expectedStatus: 200, expectedContentType: "application/x-ndjson; charset=utf-8"},
+		{name: "accept xml", path: "/v1/schools/" + testGUID, accept: "application/xml", expectedStatus: 200, expectedContentType: "application/xml; charset=utf-8"},
+		{name: "format overrides accept", path: "/v1/schools?format=yaml", accept: "text/csv", expectedStatus: 200, expectedContentType: "application/yaml; charset=utf-8"},
+		{name: "legacy route", path: "/?format=csv", expectedStatus: 200, expectedContentType: "text/csv; charset=utf-8"},
+		{name: "unsupported accept", path: "/v1/schools", accept: "text/html", expectedStatus: 406, expectedContentType: problem.ContentType},
+		{name: "unknown format", path: "/v1/schools?format=pdf", expectedStatus: 400, expectedContentType: problem.ContentType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedContentType, w.Header().Get("Content-Type"))
+			if tt.expectedStatus != http.StatusBadRequest {
+				assert.Contains(t, w.Header().Values("Vary"), "Accept")
+			}
+		})
+	}
+}
+
+func TestContentNegotiation_Bodies(t *testing.T) {
+	router, _ := setupTestRouter()
+	get := func(path string) string {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		return w.Body.String()
+	}
+
+	t.Run("csv columns follow model.Data", func(t *testing.T) {
+		rows, err := csv.NewReader(strings.NewReader(get("/v1/schools?format=csv"))).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, len(testData)+1)
+		assert.Equal(t, []string{"guid", "school", "mascot", "nickname", "location", "latlong", "ncaa", "conference"}, rows[0])
+		assert.Equal(t, testData[0].GUID, rows[1][0])
+		assert.Equal(t, testData[0].School, rows[1][1])
+	})
+
+	t.Run("ndjson has one record per line", func(t *testing.T) {
+		lines := strings.Split(strings.TrimSuffix(get("/v1/schools?format=ndjson"), "\n"), "\n")
+		assert.Len(t, lines, len(testData))
+	})
+
+	t.Run("xml", func(t *testing.T) {
+		var doc struct {
+			Schools []model.Data `xml:"school"`
+		}
+		require.NoError(t, xml.Unmarshal([]byte(get("/v1/schools?format=xml")), &doc))
+		assert.Equal(t, testData, doc.Schools)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		var result model.Data
+		require.NoError(t, yaml.Unmarshal([]byte(get("/v1/schools/"+testGUID+"?format=yaml")), &result))
+		assert.Equal(t, testData[0], result)
+	})
+}
+
+func TestGetAllData_Streams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	data := make([]model.Data, 2*render.FlushEvery+5)
+	for i := range data {
+		data[i] = model.Data{GUID: fmt.Sprintf("00000000-0000-0000-0000-%012d", i), School: "School"}
+	}
+	h := NewHandler(&mockService{data: data})
+	router := gin.New()
+	router.GET("/v1/schools", h.GetAllData)
+
+	t.Run("complete", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/v1/schools", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, w.Flushed)
+		var result []model.Data
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		assert.Equal(t, data, result)
+	})
+
+	t.Run("client gone", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequestWithContext(ctx, "GET", "/v1/schools", nil)
+		router.ServeHTTP(w, req)
+
+		// The list is left unterminated so the client can tell it is cut short.
+		assert.False(t, json.Valid(w.Body.Bytes()))
+	})
+}
+
+func TestFieldsProjection(t *testing.T) {
+	router, _ := setupTestRouter()
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	school := testData[0]
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{
+			name:     "json list",
+			path:     "/v1/schools?fields=nickname,guid,school",
+			expected: fmt.Sprintf(`[{"guid":%q,"school":%q,"nickname":%q}]`+"\n", school.GUID, school.School, school.Nickname),
+		},
+		{
+			name:     "json record with duplicates",
+			path:     "/v1/schools/" + testGUID + "?fields=school,school",
+			expected: fmt.Sprintf(`{"school":%q}`+"\n", school.School),
+		},
+		{
+			name:     "ndjson list",
+			path:     "/v1/schools?fields=school&format=ndjson",
+			expected: fmt.Sprintf(`{"school":%q}`+"\n", school.School),
+		},
+		{
+			name:     "csv list",
+			path:     "/v1/schools?fields=school,nickname&format=csv",
+			expected: fmt.Sprintf("school,nickname\n%s,%s\n", school.School, school.Nickname),
+		},
+		{
+			name:     "xml record",
+			path:     "/v1/schools/" + testGUID + "?fields=nickname&format=xml",
+			expected: xml.Header + fmt.Sprintf("<school><nickname>%s</nickname></school>", school.Nickname),
+		},
+		{
+			name:     "yaml list",
+			path:     "/v1/schools?fields=nickname&format=yaml",
+			expected: fmt.Sprintf("- nickname: %s\n", school.Nickname),
+		},
+		{
+			name:     "legacy route",
+			path:     "/?fields=guid",
+			expected: fmt.Sprintf(`[{"guid":%q}]`+"\n", school.GUID),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(tt.path)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expected, w.Body.String())
+		})
+	}
+
+	t.Run("unknown field", func(t *testing.T) {
+		w := get("/v1/schools?fields=school,coach")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var result map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		assert.Equal(t, string(problem.CodeInvalidRequest), result["code"])
+		assert.Contains(t, result["detail"], "fields[1]")
+	})
+
+	t.Run("unknown field without validation", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.GET("/v1/schools", NewHandler(&mockService{data: testData}).GetAllData)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/v1/schools?fields=coach", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `unknown value \"coach\" in fields`)
+	})
+}
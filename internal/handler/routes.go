@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"time"
+
+	"api/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Legacy root routes are deprecated aliases of v1 until they are removed.
+var (
+	legacyDeprecated = time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	legacySunset     = time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// APIVersion is one version of the public API, mounted under /<Name>.
+// New versions are registered alongside the existing ones so clients can
+// migrate at their own pace.
+type APIVersion struct {
+	// Name is the path prefix, e.g. "v1".
+	Name string
+	// Register adds the version's routes to its group.
+	Register func(rg *gin.RouterGroup)
+}
+
+// RegisterVersions mounts each API version under its own route group.
+func RegisterVersions(router gin.IRouter, versions ...APIVersion) {
+	for _, v := range versions {
+		v.Register(router.Group("/" + v.Name))
+	}
+}
+
+// V1 returns version 1 of the API.
+func (h *Handler) V1() APIVersion {
+	return APIVersion{
+		Name: "v1",
+		Register: func(rg *gin.RouterGroup) {
+			rg.GET("/schools", h.GetAllData)
+			rg.GET("/schools/:guid", h.GetDataByID)
+			rg.POST("/schools/batch", h.GetDataByIDs)
+			rg.GET("/stats", h.GetStats)
+		},
+	}
+}
+
+// RegisterLegacyRoutes registers the unversioned root routes as deprecated
+// aliases of their v1 equivalents.
+func (h *Handler) RegisterLegacyRoutes(router gin.IRouter) {
+	deprecated := func(successor func(c *gin.Context) string) gin.HandlerFunc {
+		return middleware.Deprecated(middleware.Deprecation{
+			Since:     legacyDeprecated,
+			Sunset:    legacySunset,
+			Successor: successor,
+		})
+	}
+
+	router.GET("/", deprecated(func(*gin.Context) string { return "/v1/schools" }), h.GetAllData)
+	router.GET("/:guid", deprecated(func(c *gin.Context) string { return "/v1/schools/" + c.Param("guid") }), h.GetDataByID)
+}
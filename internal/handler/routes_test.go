@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"api/internal/model"
+)
+
+func TestV1Routes(t *testing.T) {
+	router, _ := setupTestRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/schools", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Deprecation"))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/v1/schools/"+testGUID, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var result model.Data
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, testGUID, result.GUID)
+}
+
+func TestLegacyRoutes(t *testing.T) {
+	router, _ := setupTestRouter()
+
+	tests := []struct {
+		name         string
+		path         string
+		expectedLink string
+		expectedCode int
+	}{
+		{name: "list", path: "/", expectedLink: `</v1/schools>; rel="successor-version"`, expectedCode: http.StatusOK},
+		{name: "by GUID", path: "/" + testGUID, expectedLink: `</v1/schools/` + testGUID + `>; rel="successor-version"`, expectedCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.Equal(t, "@1790812800", w.Header().Get("Deprecation"))
+			assert.Equal(t, "Thu, 01 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+			assert.Equal(t, tt.expectedLink, w.Header().Get("Link"))
+		})
+	}
+}
+
+func TestRegisterVersions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewHandler(&mockService{data: testData})
+
+	v2 := APIVersion{
+		Name: "v2",
+		Register: func(rg *gin.RouterGroup) {
+			rg.GET("/schools", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"items": slices.Collect(h.service.All(c.Request.Context()))})
+			})
+		},
+	}
+
+	router := gin.New()
+	RegisterVersions(router, h.V1(), v2)
+
+	for _, path := range []string{"/v1/schools", "/v2/schools"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, path)
+	}
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"iter"
+	"reflect"
+
+	"api/internal/model"
+	"api/internal/problem"
+	"api/internal/render"
+	"api/internal/stats"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GroupByParam is the query parameter selecting the dimensions records are
+// counted by.
+const GroupByParam = "group_by"
+
+// facetedList is the list response when ?group_by= is given: the records
+// together with their counts for each dimension.
+type facetedList struct {
+	Total  int           `json:"total" xml:"total,attr" yaml:"total" doc:"Number of entries"`
+	Data   []any         `json:"data" xml:"school" yaml:"data" doc:"The entries"`
+	Facets []stats.Facet `json:"facets" xml:"facet" yaml:"facets" doc:"Entry counts for each dimension in group_by"`
+}
+
+// GetStats handles GET /v1/stats requests to return the number of schools
+// grouped by each dimension, or by those selected by ?group_by=.
+func (h *Handler) GetStats(c *gin.Context) {
+	dims, ok := groupBy(c)
+	if !ok {
+		return
+	}
+	if len(dims) == 0 {
+		dims = stats.Dimensions()
+	}
+	respondDocument(c, stats.Compute(h.service.All(c.Request.Context()), dims), render.Options{Item: "stats"})
+}
+
+// groupBy returns the dimensions selected by ?group_by=, a comma-separated
+// list. If a dimension is unknown it writes an invalid_request problem and
+// returns false.
+func groupBy(c *gin.Context) ([]stats.Dimension, bool) {
+	names, ok := listParam(c, GroupByParam, stats.DimensionNames())
+	if !ok {
+		return nil, false
+	}
+	dims := make([]stats.Dimension, len(names))
+	for i, name := range names {
+		dims[i], _ = stats.ByName(name)
+	}
+	return dims, true
+}
+
+// respondFaceted writes records with their counts by dims, with status 200
+// in the negotiated document format. Unlike respondStream it holds the
+// records in memory, since the counts come from a pass over all of them.
+func respondFaceted(c *gin.Context, records iter.Seq[model.Data], dims []stats.Dimension) {
+	opts, ok := renderOptions(c)
+	if !ok {
+		return
+	}
+	project, err := render.Projector(reflect.TypeFor[model.Data](), opts.Fields)
+	if err != nil {
+		problem.Write(c, problem.New(problem.CodeInternal, err.Error()))
+		return
+	}
+
+	s := stats.Compute(records, dims)
+	list := facetedList{Total: s.Total, Data: make([]any, 0, s.Total), Facets: s.Facets}
+	for d := range records {
+		list.Data = append(list.Data, project(d))
+	}
+	respondDocument(c, list, render.Options{Item: opts.List})
+}
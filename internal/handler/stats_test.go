@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"api/internal/middleware"
+	"api/internal/model"
+	"api/internal/problem"
+	"api/internal/stats"
+)
+
+var statsData = []model.Data{
+	{GUID: "11111111-1111-1111-1111-111111111111", School: "Iowa State University", Location: "Ames, IA, USA", NCAA: "Division I", Conference: "Big 12 Conference"},
+	{GUID: "22222222-2222-2222-2222-222222222222", School: "University of Iowa", Location: "Iowa City, IA, USA", NCAA: "Division I", Conference: "Big Ten Conference"},
+	{GUID: "33333333-3333-3333-3333-333333333333", School: "University of Nebraska", Location: "Lincoln, NE, USA", NCAA: "Division I", Conference: "Big Ten Conference"},
+}
+
+func setupStatsRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewHandler(&mockService{data: statsData})
+	router := gin.New()
+	router.Use(middleware.ValidateRequests(Spec()))
+	RegisterVersions(router, h.V1())
+	return router
+}
+
+func TestGetStats(t *testing.T) {
+	router := setupStatsRouter()
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("every dimension", func(t *testing.T) {
+		w := get("/v1/stats")
+		require.Equal(t, http.StatusOK, w.Code)
+		var result stats.Stats
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		assert.Equal(t, 3, result.Total)
+		var names []string
+		for _, f := range result.Facets {
+			names = append(names, f.Name)
+		}
+		assert.Equal(t, stats.DimensionNames(), names)
+	})
+
+	t.Run("group_by", func(t *testing.T) {
+		w := get("/v1/stats?group_by=state,conference")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"total":3,"facets":[
+			{"name":"state","counts":[{"value":"IA","count":2},{"value":"NE","count":1}]},
+			{"name":"conference","counts":[{"value":"Big Ten Conference","count":2},{"value":"Big 12 Conference","count":1}]}
+		]}`, w.Body.String())
+	})
+
+	t.Run("xml", func(t *testing.T) {
+		w := get("/v1/stats?group_by=country&format=xml")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, xml.Header+`<stats total="3"><facet name="country"><count value="USA" count="3"></count></facet></stats>`, w.Body.String())
+	})
+
+	t.Run("csv is not acceptable", func(t *testing.T) {
+		w := get("/v1/stats?format=csv")
+		assert.Equal(t, http.StatusNotAcceptable, w.Code)
+		assert.Contains(t, w.Body.String(), "supported media types are application/json, application/xml, application/yaml")
+	})
+
+	t.Run("unknown dimension", func(t *testing.T) {
+		w := get("/v1/stats?group_by=coach")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var p problem.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+		assert.Equal(t, problem.CodeInvalidRequest, p.Code)
+	})
+}
+
+func TestGetAllData_GroupBy(t *testing.T) {
+	router := setupStatsRouter()
+	get := func(path, accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("json with fields", func(t *testing.T) {
+		w := get("/v1/schools?group_by=ncaa&fields=school", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{
+			"total":3,
+			"data":[{"school":"Iowa State University"},{"school":"University of Iowa"},{"school":"University of Nebraska"}],
+			"facets":[{"name":"ncaa","counts":[{"value":"Division I","count":3}]}]
+		}`, w.Body.String())
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		w := get("/v1/schools?group_by=state&format=yaml", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var result struct {
+			Total  int           `yaml:"total"`
+			Data   []model.Data  `yaml:"data"`
+			Facets []stats.Facet `yaml:"facets"`
+		}
+		require.NoError(t, yaml.Unmarshal(w.Body.Bytes(), &result))
+		assert.Equal(t, 3, result.Total)
+		assert.Equal(t, statsData, result.Data)
+		assert.Equal(t, []stats.Facet{{Name: "state", Counts: []stats.Count{{Value: "IA", Count: 2}, {Value: "NE", Count: 1}}}}, result.Facets)
+	})
+
+	t.Run("xml", func(t *testing.T) {
+		w := get("/v1/schools?group_by=state&fields=guid", "application/xml")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, xml.Header+`<schools total="3">`+
+			`<school><guid>11111111-1111-1111-1111-111111111111</guid></school>`+
+			`<school><guid>22222222-2222-2222-2222-222222222222</guid></school>`+
+			`<school><guid>33333333-3333-3333-3333-333333333333</guid></school>`+
+			`<facet name="state"><count value="IA" count="2"></count><count value="NE" count="1"></count></facet>`+
+			`</schools>`, w.Body.String())
+	})
+
+	t.Run("csv is not acceptable", func(t *testing.T) {
+		w := get("/v1/schools?group_by=state", "text/csv")
+		assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	})
+
+	t.Run("without group_by the list is unchanged", func(t *testing.T) {
+		w := get("/v1/schools?group_by=", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var result []model.Data
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		assert.Equal(t, statsData, result)
+	})
+}
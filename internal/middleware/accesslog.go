@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Access log formats accepted by LoggerConfig.
+const (
+	// AccessLogSlog logs each request as a structured record through the global logger.
+	AccessLogSlog = "slog"
+	// AccessLogCombined writes Apache/NCSA combined log format lines.
+	AccessLogCombined = "combined"
+	// AccessLogJSON writes one JSON object per request.
+	AccessLogJSON = "json"
+)
+
+// combinedTimeFormat is the timestamp layout of the NCSA log formats.
+const combinedTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// LoggerConfig configures the request logging middleware.
+type LoggerConfig struct {
+	// Format is one of AccessLogSlog, AccessLogCombined or AccessLogJSON.
+	// Defaults to AccessLogSlog.
+	Format string
+	// Output receives combined and JSON access log lines. Defaults to
+	// stdout. It is not used by the slog format.
+	Output io.Writer
+}
+
+// AccessLogEntry describes one completed request. Its JSON encoding is the
+// schema of the JSON access log format.
+type AccessLogEntry struct {
+	Time       time.Time     `json:"time"`
+	RemoteAddr string        `json:"remote_addr"`
+	Method     string        `json:"method"`
+	Path       string        `json:"path"`
+	Query      string        `json:"query,omitempty"`
+	Protocol   string        `json:"protocol"`
+	Status     int           `json:"status"`
+	BytesIn    int64         `json:"bytes_in"`
+	BytesOut   int           `json:"bytes_out"`
+	Latency    time.Duration `json:"-"`
+	Referer    string        `json:"referer,omitempty"`
+	UserAgent  string        `json:"user_agent,omitempty"`
+	RequestID  string        `json:"request_id,omitempty"`
+}
+
+// MarshalJSON encodes the entry with latency in milliseconds.
+func (e AccessLogEntry) MarshalJSON() ([]byte, error) {
+	type entry AccessLogEntry
+	return json.Marshal(struct {
+		entry
+		LatencyMS float64 `json:"latency_ms"`
+	}{
+		entry:     entry(e),
+		LatencyMS: float64(e.Latency.Microseconds()) / 1000,
+	})
+}
+
+// redacted returns a copy of the entry with the logger's redaction rules
+// applied, since access log lines do not pass through slog.
+func (e AccessLogEntry) redacted() AccessLogEntry {
+	e.RemoteAddr = logger.Redact("client_ip", e.RemoteAddr)
+	e.Query = logger.Redact("query", e.Query)
+	e.Referer = logger.Redact("referer", e.Referer)
+	return e
+}
+
+// requestURI returns the path and query of the request.
+func (e AccessLogEntry) requestURI() string {
+	if e.Query == "" {
+		return e.Path
+	}
+	return e.Path + "?" + e.Query
+}
+
+// Combined formats the entry as an Apache/NCSA combined log line.
+func (e AccessLogEntry) Combined() string {
+	size := "-"
+	if e.BytesOut > 0 {
+		size = strconv.Itoa(e.BytesOut)
+	}
+	return fmt.Sprintf("%s - - [%s] %q %d %s %q %q\n",
+		orDash(e.RemoteAddr),
+		e.Time.Format(combinedTimeFormat),
+		e.Method+" "+e.requestURI()+" "+e.Protocol,
+		e.Status,
+		size,
+		orDash(e.Referer),
+		orDash(e.UserAgent),
+	)
+}
+
+// newAccessLogWriter returns the function that records an entry in the
+// configured format.
+func newAccessLogWriter(cfg LoggerConfig) func(c *gin.Context, e AccessLogEntry) {
+	if cfg.Format == "" || cfg.Format == AccessLogSlog {
+		return func(c *gin.Context, e AccessLogEntry) {
+			// Request ID, route and client IP come from the request-scoped
+			// logger attached by RequestID.
+			logger.Component(c.Request.Context(), "middleware").Info("HTTP Request",
+				"method", e.Method,
+				"path", e.Path,
+				"query", e.Query,
+				"status", e.Status,
+				"latency_ms", e.Latency.Milliseconds(),
+				"bytes_in", e.BytesIn,
+				"bytes_out", e.BytesOut,
+				"referer", e.Referer,
+				"user_agent", e.UserAgent,
+			)
+		}
+	}
+
+	out := cfg.Output
+	if out == nil {
+		out = os.Stdout
+	}
+	var mu sync.Mutex
+
+	return func(c *gin.Context, e AccessLogEntry) {
+		e = e.redacted()
+
+		var line []byte
+		if cfg.Format == AccessLogJSON {
+			encoded, err := json.Marshal(e)
+			if err != nil {
+				logger.Component(c.Request.Context(), "middleware").Error("Could not encode access log entry", "error", err)
+				return
+			}
+			line = append(encoded, '\n')
+		} else {
+			line = []byte(e.Combined())
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if _, err := out.Write(line); err != nil {
+			logger.Component(c.Request.Context(), "middleware").Error("Could not write access log", "error", err)
+		}
+	}
+}
+
+// orDash returns s, or "-" if s is empty, as the NCSA formats require.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"api/pkg/logger"
+)
+
+// serveAccessLogged runs one request through a router using the given access log config.
+func serveAccessLogged(cfg LoggerConfig) {
+	router := setupTestRouter()
+	router.Use(RequestID(), LoggerWithConfig(cfg))
+	router.GET("/schools", func(c *gin.Context) {
+		c.String(http.StatusOK, "hello")
+	})
+
+	req, _ := http.NewRequest("GET", "/schools?page=2", nil)
+	req.Header.Set(RequestIDHeader, "access-log-id")
+	req.Header.Set("User-Agent", "test-agent/1.0")
+	req.Header.Set("Referer", "https://example.com/")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestLoggerWithConfig_Combined(t *testing.T) {
+	var buf bytes.Buffer
+	serveAccessLogged(LoggerConfig{Format: AccessLogCombined, Output: &buf})
+
+	line := buf.String()
+	assert.True(t, strings.HasSuffix(line, "\n"))
+	assert.Contains(t, line, `"GET /schools?page=2 HTTP/1.1" 200 5 "https://example.com/" "test-agent/1.0"`)
+	assert.Regexp(t, `^\S+ - - \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] `, line)
+}
+
+func TestLoggerWithConfig_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	serveAccessLogged(LoggerConfig{Format: AccessLogJSON, Output: &buf})
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "GET", entry["method"])
+	assert.Equal(t, "/schools", entry["path"])
+	assert.Equal(t, "page=2", entry["query"])
+	assert.Equal(t, float64(200), entry["status"])
+	assert.Equal(t, float64(5), entry["bytes_out"])
+	assert.Equal(t, "test-agent/1.0", entry["user_agent"])
+	assert.Equal(t, "https://example.com/", entry["referer"])
+	assert.Equal(t, "access-log-id", entry["request_id"])
+	assert.Contains(t, entry, "latency_ms")
+}
+
+func TestLoggerWithConfig_Slog(t *testing.T) {
+	var buf bytes.Buffer
+	logger.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer logger.SetDefault(nil)
+
+	serveAccessLogged(LoggerConfig{})
+
+	out := buf.String()
+	assert.Contains(t, out, `msg="HTTP Request"`)
+	assert.Contains(t, out, "bytes_out=5")
+	assert.Contains(t, out, "user_agent=test-agent/1.0")
+	assert.Contains(t, out, "request_id=access-log-id")
+}
+
+func TestAccessLogEntry_Combined(t *testing.T) {
+	entry := AccessLogEntry{
+		Time:     time.Date(2026, 1, 5, 13, 55, 36, 0, time.FixedZone("", -7*3600)),
+		Method:   "GET",
+		Path:     "/",
+		Protocol: "HTTP/1.0",
+		Status:   304,
+	}
+
+	assert.Equal(t, `- - - [05/Jan/2026:13:55:36 -0700] "GET / HTTP/1.0" 304 - "-" "-"`+"\n", entry.Combined())
+}
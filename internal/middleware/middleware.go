@@ -2,8 +2,15 @@
 package middleware
 
 import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"api/internal/problem"
 	"api/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -17,7 +24,9 @@ const (
 	RequestIDKey = "request_id"
 )
 
-// RequestID adds a unique request ID to each request.
+// RequestID adds a unique request ID to each request. It also attaches a
+// request-scoped logger to the request context carrying the request ID,
+// matched route and client IP, retrievable with logger.FromContext.
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		requestID := c.GetHeader(RequestIDHeader)
@@ -26,12 +35,28 @@ func RequestID() gin.HandlerFunc {
 		}
 		c.Set(RequestIDKey, requestID)
 		c.Header(RequestIDHeader, requestID)
+
+		ctx := c.Request.Context()
+		reqLogger := logger.FromContext(ctx).With(
+			RequestIDKey, requestID,
+			"route", c.FullPath(),
+			"client_ip", c.ClientIP(),
+		)
+		c.Request = c.Request.WithContext(logger.WithContext(ctx, reqLogger))
+
 		c.Next()
 	}
 }
 
-// Logger logs HTTP requests and responses.
+// Logger logs HTTP requests and responses through the structured logger.
 func Logger() gin.HandlerFunc {
+	return LoggerWithConfig(LoggerConfig{})
+}
+
+// LoggerWithConfig logs HTTP requests and responses in the configured format.
+func LoggerWithConfig(cfg LoggerConfig) gin.HandlerFunc {
+	write := newAccessLogWriter(cfg)
+
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
@@ -40,60 +65,153 @@ func Logger() gin.HandlerFunc {
 		// Process request
 		c.Next()
 
-		// Calculate latency
-		latency := time.Since(start)
-
-		// Get request ID
-		requestID, _ := c.Get(RequestIDKey)
-		requestIDStr := ""
-		if id, ok := requestID.(string); ok {
-			requestIDStr = id
+		entry := AccessLogEntry{
+			Time:       start,
+			RemoteAddr: c.ClientIP(),
+			Method:     c.Request.Method,
+			Path:       path,
+			Query:      raw,
+			Protocol:   c.Request.Proto,
+			Status:     c.Writer.Status(),
+			BytesIn:    c.Request.ContentLength,
+			BytesOut:   c.Writer.Size(),
+			Latency:    time.Since(start),
+			Referer:    c.Request.Referer(),
+			UserAgent:  c.Request.UserAgent(),
+			RequestID:  c.GetString(RequestIDKey),
+		}
+		if entry.BytesIn < 0 {
+			entry.BytesIn = 0
+		}
+		if entry.BytesOut < 0 {
+			entry.BytesOut = 0
 		}
 
-		// Log request
-		logger.Info("HTTP Request",
-			"method", c.Request.Method,
-			"path", path,
-			"query", raw,
-			"status", c.Writer.Status(),
-			"latency_ms", latency.Milliseconds(),
-			"client_ip", c.ClientIP(),
-			"request_id", requestIDStr,
-		)
+		write(c, entry)
 	}
 }
 
 // Recovery recovers from panics and returns a proper error response.
 func Recovery() gin.HandlerFunc {
 	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
-		requestID, _ := c.Get(RequestIDKey)
-		requestIDStr := ""
-		if id, ok := requestID.(string); ok {
-			requestIDStr = id
-		}
-
-		logger.Error("Panic recovered",
+		logger.Component(c.Request.Context(), "middleware").Error("Panic recovered",
 			"error", recovered,
 			"path", c.Request.URL.Path,
 			"method", c.Request.Method,
-			"request_id", requestIDStr,
 		)
 
-		c.JSON(500, gin.H{
-			"error":      "Internal server error",
-			"request_id": requestIDStr,
-		})
-		c.Abort()
+		problem.Abort(c, problem.New(problem.CodeInternal, ""))
 	})
 }
 
-// CORS adds CORS headers to responses.
+// NoRoute responds to requests for unknown paths with a route_not_found
+// problem. Register it with gin.Engine.NoRoute.
+func NoRoute() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		problem.Write(c, problem.Newf(problem.CodeRouteNotFound, "no route matches %s", c.Request.URL.Path))
+	}
+}
+
+// NoMethod responds to requests for known paths with an unsupported method
+// with a method_not_allowed problem and an Allow header listing the methods
+// routes has for the path. Register it with gin.Engine.NoMethod and set
+// HandleMethodNotAllowed on the engine.
+func NoMethod(routes func() gin.RoutesInfo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed := allowedMethods(routes(), c.Request.URL.Path)
+		if len(allowed) > 0 {
+			c.Header("Allow", strings.Join(allowed, ", "))
+		}
+		problem.Write(c, problem.Newf(problem.CodeMethodNotAllowed, "method %s is not allowed for %s", c.Request.Method, c.Request.URL.Path))
+	}
+}
+
+// allowedMethods returns the methods of the routes matching path, in
+// registration order.
+func allowedMethods(routes gin.RoutesInfo, path string) []string {
+	var methods []string
+	for _, r := range routes {
+		if routeMatches(r.Path, path) && !slices.Contains(methods, r.Method) {
+			methods = append(methods, r.Method)
+		}
+	}
+	return methods
+}
+
+// routeMatches reports whether path matches a gin route pattern with
+// :param and *wildcard segments.
+func routeMatches(pattern, path string) bool {
+	patternSegs := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegs := strings.Split(strings.Trim(path, "/"), "/")
+	for i, seg := range patternSegs {
+		if strings.HasPrefix(seg, "*") {
+			return true
+		}
+		if i >= len(pathSegs) {
+			return false
+		}
+		if !strings.HasPrefix(seg, ":") && seg != pathSegs[i] {
+			return false
+		}
+		if strings.HasPrefix(seg, ":") && pathSegs[i] == "" {
+			return false
+		}
+	}
+	return len(patternSegs) == len(pathSegs)
+}
+
+// AllowedOrigins is a replaceable set of origins allowed by CORS. It is
+// safe for concurrent use, so origins can be changed while serving.
+type AllowedOrigins struct {
+	origins atomic.Pointer[[]string]
+}
+
+// NewAllowedOrigins creates a set of allowed origins. "*" allows any origin.
+func NewAllowedOrigins(origins []string) *AllowedOrigins {
+	o := &AllowedOrigins{}
+	o.Set(origins)
+	return o
+}
+
+// Set replaces the allowed origins.
+func (o *AllowedOrigins) Set(origins []string) {
+	copied := append([]string(nil), origins...)
+	o.origins.Store(&copied)
+}
+
+// match returns the Access-Control-Allow-Origin value for a request from
+// origin, or false if the origin is not allowed.
+func (o *AllowedOrigins) match(origin string) (string, bool) {
+	for _, allowed := range *o.origins.Load() {
+		if allowed == "*" {
+			return "*", true
+		}
+		if origin != "" && strings.EqualFold(allowed, origin) {
+			return origin, true
+		}
+	}
+	return "", false
+}
+
+// CORS adds CORS headers to responses, allowing any origin.
 func CORS() gin.HandlerFunc {
+	return CORSWithOrigins(NewAllowedOrigins([]string{"*"}))
+}
+
+// CORSWithOrigins adds CORS headers to responses for requests from allowed
+// origins. Requests from other origins get no CORS headers, so browsers
+// block them.
+func CORSWithOrigins(origins *AllowedOrigins) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
+		c.Writer.Header().Add("Vary", "Origin")
+
+		allowOrigin, ok := origins.match(c.GetHeader("Origin"))
+		if ok {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", allowOrigin)
+			c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+			c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+			c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
+		}
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -103,3 +221,67 @@ func CORS() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// AdminAuth rejects requests that do not carry "Authorization: Bearer
+// <token>" matching the token returned by token. The token is read on each
+// request, so it can be rotated while serving; while it is empty every
+// request is refused with admin_disabled.
+func AdminAuth(token func() string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		want := token()
+		if want == "" {
+			problem.Abort(c, problem.New(problem.CodeAdminDisabled, "no admin token is configured"))
+			return
+		}
+
+		got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+			logger.Component(c.Request.Context(), "middleware").Warn("Admin request rejected",
+				"path", c.Request.URL.Path,
+				"method", c.Request.Method,
+			)
+			c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+			problem.Abort(c, problem.New(problem.CodeUnauthorized, "a valid bearer token is required"))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Deprecation describes a deprecated route.
+type Deprecation struct {
+	// Since is when the route was deprecated.
+	Since time.Time
+	// Sunset is when the route will be removed; zero omits the header.
+	Sunset time.Time
+	// Successor returns the path of the route replacing the requested one;
+	// nil omits the link.
+	Successor func(c *gin.Context) string
+}
+
+// Deprecated marks responses as coming from a deprecated route with the
+// Deprecation (RFC 9745), Sunset (RFC 8594) and successor-version Link
+// headers.
+func Deprecated(d Deprecation) gin.HandlerFunc {
+	deprecation := fmt.Sprintf("@%d", d.Since.Unix())
+	var sunset string
+	if !d.Sunset.IsZero() {
+		sunset = d.Sunset.UTC().Format(http.TimeFormat)
+	}
+
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		if sunset != "" {
+			c.Header("Sunset", sunset)
+		}
+		if d.Successor != nil {
+			c.Header("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, d.Successor(c)))
+		}
+
+		logger.Component(c.Request.Context(), "middleware").Debug("Deprecated route used",
+			"path", c.Request.URL.Path,
+		)
+		c.Next()
+	}
+}
@@ -1,12 +1,18 @@
 package middleware
 
 import (
+	"bytes"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+
+	"api/internal/problem"
+	"api/pkg/logger"
 )
 
 func setupTestRouter() *gin.Engine {
@@ -75,3 +81,174 @@ func TestCORS_OPTIONS(t *testing.T) {
 
 	assert.Equal(t, 204, w.Code)
 }
+
+func TestRequestID_ContextLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer logger.SetDefault(nil)
+
+	router := setupTestRouter()
+	router.Use(RequestID())
+	router.GET("/items/:id", func(c *gin.Context) {
+		logger.FromContext(c.Request.Context()).Info("handled")
+		c.Status(200)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/items/42", nil)
+	req.Header.Set(RequestIDHeader, "ctx-request-id")
+	router.ServeHTTP(w, req)
+
+	out := buf.String()
+	assert.Contains(t, out, "request_id=ctx-request-id")
+	assert.Contains(t, out, "route=/items/:id")
+	assert.Contains(t, out, "client_ip=")
+}
+
+func TestCORSWithOrigins(t *testing.T) {
+	origins := NewAllowedOrigins([]string{"https://app.example.com"})
+
+	router := setupTestRouter()
+	router.Use(CORSWithOrigins(origins))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok"})
+	})
+
+	request := func(origin string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/test", nil)
+		req.Header.Set("Origin", origin)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("https://app.example.com")
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Origin", w.Header().Get("Vary"))
+
+	w = request("https://evil.example.com")
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+	// Origins can be replaced while serving
+	origins.Set([]string{"https://evil.example.com"})
+	w = request("https://evil.example.com")
+	assert.Equal(t, "https://evil.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestAdminAuth(t *testing.T) {
+	token := "s3cr3t"
+
+	router := setupTestRouter()
+	router.Use(AdminAuth(func() string { return token }))
+	router.GET("/admin", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok"})
+	})
+
+	tests := []struct {
+		name           string
+		token          string
+		authorization  string
+		expectedStatus int
+	}{
+		{name: "valid token", token: "s3cr3t", authorization: "Bearer s3cr3t", expectedStatus: 200},
+		{name: "wrong token", token: "s3cr3t", authorization: "Bearer nope", expectedStatus: 401},
+		{name: "missing header", token: "s3cr3t", expectedStatus: 401},
+		{name: "wrong scheme", token: "s3cr3t", authorization: "Basic s3cr3t", expectedStatus: 401},
+		{name: "no token configured", token: "", authorization: "Bearer ", expectedStatus: 403},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token = tt.token
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/admin", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == 401 {
+				assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+				assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+				assert.Contains(t, w.Body.String(), `"code":"unauthorized"`)
+			}
+			if tt.expectedStatus == 403 {
+				assert.Empty(t, w.Header().Get("WWW-Authenticate"))
+				assert.Contains(t, w.Body.String(), `"code":"admin_disabled"`)
+			}
+		})
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	router := setupTestRouter()
+	router.GET("/old/:id", Deprecated(Deprecation{
+		Since:     time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Sunset:    time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC),
+		Successor: func(c *gin.Context) string { return "/v1/new/" + c.Param("id") },
+	}), func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok"})
+	})
+	router.GET("/undated", Deprecated(Deprecation{Since: time.Unix(0, 0)}), func(c *gin.Context) {
+		c.Status(200)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/old/42", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "@1767225600", w.Header().Get("Deprecation"))
+	assert.Equal(t, "Wed, 01 Jul 2026 00:00:00 GMT", w.Header().Get("Sunset"))
+	assert.Equal(t, `</v1/new/42>; rel="successor-version"`, w.Header().Get("Link"))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/undated", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "@0", w.Header().Get("Deprecation"))
+	assert.Empty(t, w.Header().Get("Sunset"))
+	assert.Empty(t, w.Header().Get("Link"))
+}
+
+func TestRecovery(t *testing.T) {
+	router := setupTestRouter()
+	router.Use(Recovery(), RequestID())
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/panic", nil)
+	req.Header.Set(RequestIDHeader, "panic-request")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 500, w.Code)
+	assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `"code":"internal_error"`)
+	assert.Contains(t, w.Body.String(), `"request_id":"panic-request"`)
+	assert.NotContains(t, w.Body.String(), "boom")
+}
+
+func TestRouteMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "/", path: "/", want: true},
+		{pattern: "/", path: "/x", want: false},
+		{pattern: "/:guid", path: "/x", want: true},
+		{pattern: "/:guid", path: "/", want: false},
+		{pattern: "/v1/schools", path: "/v1/schools/", want: true},
+		{pattern: "/v1/schools/:guid", path: "/v1/schools/abc", want: true},
+		{pattern: "/v1/schools/:guid", path: "/v1/schools", want: false},
+		{pattern: "/static/*file", path: "/static/a/b.css", want: true},
+		{pattern: "/admin/log-level", path: "/admin/levels", want: false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, routeMatches(tt.pattern, tt.path), "%s vs %s", tt.pattern, tt.path)
+	}
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"api/internal/openapi"
+	"api/internal/problem"
+	"api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxValidatedBodySize is the largest request body ValidateRequests reads.
+const MaxValidatedBodySize = 1 << 20
+
+// ValidateRequests checks the path parameters, query parameters and JSON
+// body of each request against the operation doc describes for the
+// matched route, so handlers receive only values that match the schema.
+// Invalid requests get an invalid_request problem listing every invalid
+// field, or the parameter's own error code when the first invalid value
+// is a parameter that declares one. Routes not in doc are not checked.
+func ValidateRequests(doc *openapi.Document) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			c.Next()
+			return
+		}
+		op := doc.Operation(c.Request.Method, route)
+		if op == nil {
+			c.Next()
+			return
+		}
+
+		req := openapi.Request{
+			PathParams: make(map[string]string, len(c.Params)),
+			Query:      c.Request.URL.Query(),
+		}
+		for _, p := range c.Params {
+			req.PathParams[p.Key] = p.Value
+		}
+
+		if op.RequestBody != nil && c.Request.Body != nil {
+			body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, MaxValidatedBodySize))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				problem.Abort(c, problem.Newf(problem.CodeBodyTooLarge, "request body exceeds %d bytes", MaxValidatedBodySize))
+				return
+			}
+			if err != nil {
+				problem.Abort(c, problem.New(problem.CodeInvalidBody, "could not read request body"))
+				return
+			}
+			// Hand the body on to the handler.
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			req.Body = body
+		}
+
+		fieldErrs, err := doc.ValidateRequest(op, req)
+		if err != nil {
+			problem.Abort(c, problem.New(problem.CodeInvalidBody, err.Error()))
+			return
+		}
+		if len(fieldErrs) == 0 {
+			c.Next()
+			return
+		}
+
+		logger.Component(c.Request.Context(), "middleware").Warn("Request validation failed",
+			"path", c.Request.URL.Path,
+			"errors", len(fieldErrs),
+		)
+		problem.Abort(c, validationProblem(op, fieldErrs))
+	}
+}
+
+// validationProblem builds the problem reporting fieldErrs.
+func validationProblem(op *openapi.Operation, fieldErrs []openapi.FieldError) *problem.Problem {
+	code := problem.CodeInvalidRequest
+	first := fieldErrs[0]
+	for _, p := range op.Parameters {
+		if p.Name == first.Field && p.In == first.In && p.ErrorCode != "" {
+			code = problem.Code(p.ErrorCode)
+			break
+		}
+	}
+
+	details := make([]string, len(fieldErrs))
+	errs := make([]problem.FieldError, len(fieldErrs))
+	for i, fe := range fieldErrs {
+		details[i] = describeField(fe) + " " + fe.Message
+		errs[i] = problem.FieldError{Field: fe.Field, In: fe.In, Message: fe.Message}
+	}
+
+	p := problem.New(code, strings.Join(details, "; "))
+	p.Errors = errs
+	return p
+}
+
+// describeField names an invalid value for the problem detail.
+func describeField(fe openapi.FieldError) string {
+	switch {
+	case fe.In == openapi.InBody && fe.Field == "":
+		return "body"
+	case fe.In == openapi.InBody:
+		return fmt.Sprintf("body field %s", fe.Field)
+	default:
+		return fmt.Sprintf("%s parameter %s", fe.In, fe.Field)
+	}
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"api/internal/openapi"
+	"api/internal/problem"
+)
+
+func TestValidateRequests(t *testing.T) {
+	doc := openapi.New(openapi.Info{Title: "Test", Version: "1"})
+	doc.Add("GET", "/items/:id", &openapi.Operation{
+		Parameters: []*openapi.Parameter{
+			{Name: "id", In: openapi.InPath, Required: true, Schema: &openapi.Schema{Type: "string", Pattern: "^[0-9]+$"}, ErrorCode: "invalid_guid"},
+			{Name: "limit", In: openapi.InQuery, Schema: &openapi.Schema{Type: "integer"}},
+		},
+	})
+	doc.Add("PUT", "/items/:id", &openapi.Operation{
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content: map[string]*openapi.MediaType{"application/json": {Schema: &openapi.Schema{
+				Type:       "object",
+				Properties: map[string]*openapi.Schema{"name": {Type: "string"}},
+				Required:   []string{"name"},
+			}}},
+		},
+	})
+
+	router := setupTestRouter()
+	router.Use(ValidateRequests(doc))
+	router.GET("/items/:id", func(c *gin.Context) { c.String(200, "ok") })
+	router.PUT("/items/:id", func(c *gin.Context) {
+		// The handler still sees the body.
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(200, string(body))
+	})
+	router.GET("/undocumented", func(c *gin.Context) { c.String(200, "ok") })
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedCode   problem.Code
+		expectedErrors []problem.FieldError
+		expectedBody   string
+	}{
+		{name: "valid", method: "GET", path: "/items/1?limit=2", expectedStatus: 200, expectedBody: "ok"},
+		{name: "undocumented route", method: "GET", path: "/undocumented?limit=x", expectedStatus: 200, expectedBody: "ok"},
+		{
+			name: "parameter error code", method: "GET", path: "/items/abc?limit=x",
+			expectedStatus: 400, expectedCode: "invalid_guid",
+			expectedErrors: []problem.FieldError{
+				{Field: "id", In: "path", Message: "must match the pattern ^[0-9]+$"},
+				{Field: "limit", In: "query", Message: "must be an integer"},
+			},
+		},
+		{
+			name: "generic code", method: "GET", path: "/items/1?limit=x",
+			expectedStatus: 400, expectedCode: problem.CodeInvalidRequest,
+			expectedErrors: []problem.FieldError{{Field: "limit", In: "query", Message: "must be an integer"}},
+		},
+		{name: "valid body", method: "PUT", path: "/items/1", body: `{"name":"x"}`, expectedStatus: 200, expectedBody: `{"name":"x"}`},
+		{
+			name: "invalid body field", method: "PUT", path: "/items/1", body: `{"name":1}`,
+			expectedStatus: 400, expectedCode: problem.CodeInvalidRequest,
+			expectedErrors: []problem.FieldError{{Field: "name", In: "body", Message: "must be a string"}},
+		},
+		{name: "malformed body", method: "PUT", path: "/items/1", body: `{`, expectedStatus: 400, expectedCode: problem.CodeInvalidBody},
+		{name: "missing body", method: "PUT", path: "/items/1", expectedStatus: 400, expectedCode: problem.CodeInvalidBody},
+		{
+			name: "body too large", method: "PUT", path: "/items/1",
+			body:           `{"name":"` + strings.Repeat("x", MaxValidatedBodySize) + `"}`,
+			expectedStatus: 413, expectedCode: problem.CodeBodyTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedCode == "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+				return
+			}
+
+			var p problem.Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+			assert.Equal(t, tt.expectedCode, p.Code)
+			assert.Equal(t, tt.expectedErrors, p.Errors)
+		})
+	}
+}
@@ -1,42 +1,78 @@
 // Package model provides data models and validation functions.
 package model
 
-// Data represents a university/school data entry.
+import (
+	"regexp"
+	"strings"
+)
+
+// GUIDPattern is the regular expression matched by ValidateGUID.
+const GUIDPattern = "^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$"
+
+// Data represents a university/school data entry. Field names are the same
+// in every response format; the doc tags describe the fields in the
+// OpenAPI document.
 type Data struct {
-	GUID       string `json:"guid"`
-	School     string `json:"school"`
-	Mascot     string `json:"mascot"`
-	Nickname   string `json:"nickname"`
-	Location   string `json:"location"`
-	LatLong    string `json:"latlong"`
-	NCAA       string `json:"ncaa,omitempty"`
-	Conference string `json:"conference,omitempty"`
+	GUID       string `json:"guid" xml:"guid" yaml:"guid" doc:"Unique identifier of the entry"`
+	School     string `json:"school" xml:"school" yaml:"school" doc:"Name of the school or university"`
+	Mascot     string `json:"mascot" xml:"mascot" yaml:"mascot" doc:"Mascot"`
+	Nickname   string `json:"nickname" xml:"nickname" yaml:"nickname" doc:"Nickname of the athletic teams"`
+	Location   string `json:"location" xml:"location" yaml:"location" doc:"City, state and country"`
+	LatLong    string `json:"latlong" xml:"latlong" yaml:"latlong" doc:"Latitude and longitude, comma-separated"`
+	NCAA       string `json:"ncaa,omitempty" xml:"ncaa,omitempty" yaml:"ncaa,omitempty" doc:"NCAA division"`
+	Conference string `json:"conference,omitempty" xml:"conference,omitempty" yaml:"conference,omitempty" doc:"Athletic conference"`
 }
 
+// guidRegexp matches GUIDPattern.
+var guidRegexp = regexp.MustCompile(GUIDPattern)
+
 // ValidateGUID validates if a string is a valid GUID format.
 // A GUID should be in the format: xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
 func ValidateGUID(guid string) bool {
-	if len(guid) != 36 {
-		return false
-	}
+	return guidRegexp.MatchString(guid)
+}
 
-	// Check format: xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
-	expectedDashes := []int{8, 13, 18, 23}
-	for _, pos := range expectedDashes {
-		if guid[pos] != '-' {
-			return false
-		}
+// Field returns the value of the field with JSON name name, or "" if there
+// is no such field.
+func (d Data) Field(name string) string {
+	switch name {
+	case "guid":
+		return d.GUID
+	case "school":
+		return d.School
+	case "mascot":
+		return d.Mascot
+	case "nickname":
+		return d.Nickname
+	case "location":
+		return d.Location
+	case "latlong":
+		return d.LatLong
+	case "ncaa":
+		return d.NCAA
+	case "conference":
+		return d.Conference
+	default:
+		return ""
 	}
+}
 
-	// Check that all other characters are hexadecimal
-	for i, r := range guid {
-		if i == 8 || i == 13 || i == 18 || i == 23 {
-			continue
-		}
-		if !((r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')) {
-			return false
-		}
+// State returns the state or region part of Location, the second to last of
+// its comma-separated parts, or "" if Location has fewer than three parts.
+func (d Data) State() string {
+	parts := strings.Split(d.Location, ",")
+	if len(parts) < 3 {
+		return ""
 	}
+	return strings.TrimSpace(parts[len(parts)-2])
+}
 
-	return true
+// Country returns the country part of Location, its last comma-separated
+// part, or "" if Location has fewer than two parts.
+func (d Data) Country() string {
+	parts := strings.Split(d.Location, ",")
+	if len(parts) < 2 {
+		return ""
+	}
+	return strings.TrimSpace(parts[len(parts)-1])
 }
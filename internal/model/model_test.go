@@ -1,6 +1,10 @@
 package model
 
-import "testing"
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
 
 func TestValidateGUID(t *testing.T) {
 	tests := []struct {
@@ -59,3 +63,48 @@ func TestValidateGUID(t *testing.T) {
 		})
 	}
 }
+
+func TestData_StateAndCountry(t *testing.T) {
+	tests := []struct {
+		location string
+		state    string
+		country  string
+	}{
+		{location: "Ames, IA, USA", state: "IA", country: "USA"},
+		{location: "New York, New York, NY, USA", state: "NY", country: "USA"},
+		{location: "Toronto, Canada", state: "", country: "Canada"},
+		{location: "Somewhere", state: "", country: ""},
+		{location: "", state: "", country: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			d := Data{Location: tt.location}
+			if got := d.State(); got != tt.state {
+				t.Errorf("State() = %q, want %q", got, tt.state)
+			}
+			if got := d.Country(); got != tt.country {
+				t.Errorf("Country() = %q, want %q", got, tt.country)
+			}
+		})
+	}
+}
+
+func TestData_Field(t *testing.T) {
+	// Every field is reachable by its JSON name.
+	var d Data
+	v := reflect.ValueOf(&d).Elem()
+	for i := range v.NumField() {
+		v.Field(i).SetString("value " + v.Type().Field(i).Name)
+	}
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if got := d.Field(name); got != "value "+f.Name {
+			t.Errorf("Field(%q) = %q, want %q", name, got, "value "+f.Name)
+		}
+	}
+	if got := d.Field("unknown"); got != "" {
+		t.Errorf("Field(%q) = %q, want empty", "unknown", got)
+	}
+}
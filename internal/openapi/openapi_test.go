@@ -0,0 +1,60 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathTemplate(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+		params   []string
+	}{
+		{path: "/", expected: "/"},
+		{path: "/v1/schools", expected: "/v1/schools"},
+		{path: "/:guid", expected: "/{guid}", params: []string{"guid"}},
+		{path: "/v1/schools/:guid", expected: "/v1/schools/{guid}", params: []string{"guid"}},
+		{path: "/files/*path", expected: "/files/{path}", params: []string{"path"}},
+		{path: "/a/:x/b/:y", expected: "/a/{x}/b/{y}", params: []string{"x", "y"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, PathTemplate(tt.path))
+			assert.Equal(t, tt.params, PathParams(tt.path))
+		})
+	}
+}
+
+func TestDocument(t *testing.T) {
+	doc := New(Info{Title: "Test", Version: "1"})
+	list := &Operation{OperationID: "list", Responses: map[string]*Response{"200": {Description: "ok"}}}
+	get := &Operation{OperationID: "get", Responses: map[string]*Response{"200": {Description: "ok"}}}
+	put := &Operation{OperationID: "put", Responses: map[string]*Response{"200": {Description: "ok"}}}
+	doc.Add("GET", "/things", list)
+	doc.Add("GET", "/things/:id", get)
+	doc.Add("PUT", "/things/:id", put)
+
+	assert.Equal(t, []Route{
+		{Method: "GET", Path: "/things"},
+		{Method: "GET", Path: "/things/{id}"},
+		{Method: "PUT", Path: "/things/{id}"},
+	}, doc.Routes())
+	assert.Same(t, get, doc.Operation("GET", "/things/:id"))
+	assert.Same(t, put, doc.Operation("PUT", "/things/{id}"))
+	assert.Nil(t, doc.Operation("DELETE", "/things/:id"))
+	assert.Nil(t, doc.Operation("GET", "/other"))
+
+	body, err := json.Marshal(doc)
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(body, &decoded))
+	assert.Equal(t, "3.1.0", decoded["openapi"])
+	paths := decoded["paths"].(map[string]any)
+	assert.Contains(t, paths["/things/{id}"], "get")
+	assert.Contains(t, paths["/things/{id}"], "put")
+}
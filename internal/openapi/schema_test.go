@@ -0,0 +1,41 @@
+package openapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sample struct {
+	Name     string            `json:"name" doc:"The name"`
+	Count    int               `json:"count,omitempty"`
+	Ratio    float64           `json:"ratio"`
+	Enabled  bool              `json:"enabled"`
+	Tags     []string          `json:"tags,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Created  time.Time         `json:"created"`
+	Parent   *sample           `json:"-"`
+	Untagged string
+	hidden   string
+}
+
+func TestSchemaOf(t *testing.T) {
+	s := SchemaOf(sample{hidden: ""})
+
+	assert.Equal(t, "object", s.Type)
+	assert.Equal(t, []string{"name", "ratio", "enabled", "created", "Untagged"}, s.Required)
+	assert.Len(t, s.Properties, 8)
+	assert.Equal(t, &Schema{Type: "string", Description: "The name"}, s.Properties["name"])
+	assert.Equal(t, "integer", s.Properties["count"].Type)
+	assert.Equal(t, "number", s.Properties["ratio"].Type)
+	assert.Equal(t, "boolean", s.Properties["enabled"].Type)
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Type: "string"}}, s.Properties["tags"])
+	assert.Equal(t, &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}, s.Properties["labels"])
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, s.Properties["created"])
+	assert.NotContains(t, s.Properties, "Parent")
+	assert.NotContains(t, s.Properties, "hidden")
+
+	assert.Equal(t, "object", SchemaOf(&sample{}).Type)
+	assert.Equal(t, "#/components/schemas/Data", Ref("Data").Ref)
+}
@@ -0,0 +1,387 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Parameter and field locations reported in FieldError.In.
+const (
+	InPath  = "path"
+	InQuery = "query"
+	InBody  = "body"
+)
+
+// FieldError is a request value that does not match its schema.
+type FieldError struct {
+	// Field is the parameter name, or the dotted path of a body field
+	// with [i] for array items. It is empty for the body as a whole.
+	Field string `json:"field"`
+	// In is InPath, InQuery or InBody.
+	In      string `json:"in"`
+	Message string `json:"message"`
+}
+
+// Request is the part of an HTTP request checked by ValidateRequest.
+type Request struct {
+	PathParams map[string]string
+	Query      url.Values
+	// Body is the raw request body; nil if the request has none.
+	Body []byte
+}
+
+// ValidateRequest checks r against the parameters and JSON request body
+// of op, returning an error for each value that does not match its schema.
+// Path parameters come first, then query parameters in declaration order,
+// then body fields in name order. Query parameters not declared by op are
+// ignored. A body that is not valid JSON is returned as an error.
+func (d *Document) ValidateRequest(op *Operation, r Request) ([]FieldError, error) {
+	var errs []FieldError
+	for _, in := range []string{InPath, InQuery} {
+		for _, p := range op.Parameters {
+			if p.In != in {
+				continue
+			}
+			var values []string
+			if in == InPath {
+				if v, ok := r.PathParams[p.Name]; ok {
+					values = []string{v}
+				}
+			} else {
+				values = r.Query[p.Name]
+			}
+			errs = append(errs, d.validateParam(p, values)...)
+		}
+	}
+
+	if op.RequestBody == nil {
+		return errs, nil
+	}
+	media, ok := op.RequestBody.Content["application/json"]
+	if !ok {
+		return errs, nil
+	}
+	if len(bytes.TrimSpace(r.Body)) == 0 {
+		if op.RequestBody.Required {
+			return errs, errors.New("request body is required")
+		}
+		return errs, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(r.Body))
+	dec.UseNumber()
+	var body any
+	if err := dec.Decode(&body); err != nil {
+		return errs, fmt.Errorf("request body is not valid JSON: %w", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errs, errors.New("request body must contain a single JSON value")
+	}
+	for _, fe := range d.Validate(media.Schema, body, "") {
+		fe.In = InBody
+		errs = append(errs, fe)
+	}
+	return errs, nil
+}
+
+// validateParam checks the values given for p.
+func (d *Document) validateParam(p *Parameter, values []string) []FieldError {
+	fail := func(format string, args ...any) []FieldError {
+		return []FieldError{{Field: p.Name, In: p.In, Message: fmt.Sprintf(format, args...)}}
+	}
+
+	if len(values) == 0 {
+		if p.Required {
+			return fail("is required")
+		}
+		return nil
+	}
+
+	schema := d.resolve(p.Schema)
+	exploded := schema.Type == "array" && (p.Explode == nil || *p.Explode)
+	if !exploded && len(values) > 1 {
+		return fail("must be given once")
+	}
+	if schema.Type == "array" && !exploded {
+		// An empty value is an empty list.
+		values = strings.Split(values[0], ",")
+		if values[0] == "" && len(values) == 1 {
+			values = nil
+		}
+	}
+
+	var value any
+	if schema.Type == "array" {
+		items := make([]any, len(values))
+		for i, raw := range values {
+			v, err := coerce(d.resolve(schema.Items), raw)
+			if err != nil {
+				return fail("item %d %s", i, err)
+			}
+			items[i] = v
+		}
+		value = items
+	} else {
+		v, err := coerce(schema, values[0])
+		if err != nil {
+			return fail("%s", err)
+		}
+		value = v
+	}
+
+	errs := d.Validate(schema, value, p.Name)
+	for i := range errs {
+		errs[i].In = p.In
+	}
+	return errs
+}
+
+// coerce converts a raw parameter value to the JSON type of s.
+func coerce(s *Schema, raw string) (any, error) {
+	switch s.Type {
+	case "integer", "number":
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("must be %s", article(s.Type))
+		}
+		return json.Number(raw), nil
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errors.New("must be true or false")
+		}
+		return b, nil
+	default:
+		return raw, nil
+	}
+}
+
+// Validate checks value, decoded from JSON with json.Decoder.UseNumber,
+// against s. Field names in the errors are prefixed with field.
+func (d *Document) Validate(s *Schema, value any, field string) []FieldError {
+	s = d.resolve(s)
+	fail := func(format string, args ...any) []FieldError {
+		return []FieldError{{Field: field, Message: fmt.Sprintf(format, args...)}}
+	}
+
+	if len(s.OneOf) > 0 {
+		matched := 0
+		for _, alt := range s.OneOf {
+			if len(d.Validate(alt, value, field)) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			return fail("must match exactly one of %d schemas, matched %d", len(s.OneOf), matched)
+		}
+	}
+	if s.Type != "" && !hasType(value, s.Type) {
+		return fail("must be %s", article(s.Type))
+	}
+	if len(s.Enum) > 0 && !inEnum(value, s.Enum) {
+		return fail("must be one of %s", formatEnum(s.Enum))
+	}
+
+	switch v := value.(type) {
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.MinLength != nil && n < *s.MinLength {
+			return fail("must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			return fail("must be at most %d characters", *s.MaxLength)
+		}
+		if s.Pattern != "" && !compilePattern(s.Pattern).MatchString(v) {
+			return fail("must match the pattern %s", s.Pattern)
+		}
+	case json.Number:
+		f, _ := v.Float64()
+		if s.Minimum != nil && f < *s.Minimum {
+			return fail("must be at least %s", formatNumber(*s.Minimum))
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			return fail("must be at most %s", formatNumber(*s.Maximum))
+		}
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			return fail("must have at least %s", countItems(*s.MinItems))
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			return fail("must have at most %s", countItems(*s.MaxItems))
+		}
+		var errs []FieldError
+		for i, item := range v {
+			if s.Items != nil {
+				errs = append(errs, d.Validate(s.Items, item, fmt.Sprintf("%s[%d]", field, i))...)
+			}
+		}
+		return errs
+	case map[string]any:
+		return d.validateObject(s, v, field)
+	}
+	return nil
+}
+
+// validateObject checks the properties of an object.
+func (d *Document) validateObject(s *Schema, obj map[string]any, field string) []FieldError {
+	var errs []FieldError
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			errs = append(errs, FieldError{Field: joinField(field, name), Message: "is required"})
+		}
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if prop, ok := s.Properties[name]; ok {
+			errs = append(errs, d.Validate(prop, obj[name], joinField(field, name))...)
+		} else if s.AdditionalProperties != nil {
+			errs = append(errs, d.Validate(s.AdditionalProperties, obj[name], joinField(field, name))...)
+		}
+	}
+	return errs
+}
+
+// resolve follows a reference to a component schema. Unknown references
+// resolve to the empty schema, which accepts anything.
+func (d *Document) resolve(s *Schema) *Schema {
+	if s == nil {
+		return &Schema{}
+	}
+	if name, ok := strings.CutPrefix(s.Ref, "#/components/schemas/"); ok {
+		if target, ok := d.Components.Schemas[name]; ok {
+			return d.resolve(target)
+		}
+		return &Schema{}
+	}
+	return s
+}
+
+// hasType reports whether a decoded JSON value has the JSON Schema type t.
+func hasType(value any, t string) bool {
+	switch v := value.(type) {
+	case nil:
+		return t == "null"
+	case bool:
+		return t == "boolean"
+	case string:
+		return t == "string"
+	case json.Number:
+		if t == "number" {
+			return true
+		}
+		f, err := v.Float64()
+		return t == "integer" && err == nil && f == math.Trunc(f)
+	case []any:
+		return t == "array"
+	case map[string]any:
+		return t == "object"
+	default:
+		return false
+	}
+}
+
+// inEnum reports whether value equals one of enum.
+func inEnum(value any, enum []any) bool {
+	for _, e := range enum {
+		if n, ok := value.(json.Number); ok {
+			f, _ := n.Float64()
+			if ef, ok := toFloat(e); ok && ef == f {
+				return true
+			}
+			continue
+		}
+		if e == value {
+			return true
+		}
+	}
+	return false
+}
+
+// toFloat converts a numeric enum value to float64.
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+// formatEnum lists enum values for an error message.
+func formatEnum(enum []any) string {
+	parts := make([]string, len(enum))
+	for i, e := range enum {
+		if s, ok := e.(string); ok {
+			parts[i] = strconv.Quote(s)
+		} else {
+			parts[i] = fmt.Sprint(e)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatNumber formats a bound without a trailing ".0".
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// article returns a JSON type name with its indefinite article.
+func article(t string) string {
+	switch t {
+	case "array", "integer", "object":
+		return "an " + t
+	default:
+		return "a " + t
+	}
+}
+
+// countItems returns "n items", or "1 item".
+func countItems(n int) string {
+	if n == 1 {
+		return "1 item"
+	}
+	return fmt.Sprintf("%d items", n)
+}
+
+// joinField appends name to a dotted field path.
+func joinField(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
+
+// patterns caches compiled schema patterns.
+var patterns sync.Map
+
+// compilePattern returns the compiled regular expression for pattern. An
+// invalid pattern matches nothing.
+func compilePattern(pattern string) *regexp.Regexp {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		re = regexp.MustCompile(`[^\s\S]`)
+	}
+	patterns.Store(pattern, re)
+	return re
+}
@@ -0,0 +1,185 @@
+package openapi
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func validationDoc() (*Document, *Operation) {
+	doc := New(Info{Title: "Test", Version: "1"})
+	doc.Components.Schemas["Item"] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"name":  {Type: "string", MinLength: ptr(1), MaxLength: ptr(5)},
+			"count": {Type: "integer", Minimum: ptr(0.0), Maximum: ptr(10.0)},
+			"kind":  {Type: "string", Enum: []any{"a", "b"}},
+			"tags":  {Type: "array", MinItems: ptr(1), MaxItems: ptr(2), Items: &Schema{Type: "string", Pattern: "^[a-z]+$"}},
+			"meta":  {Type: "object", AdditionalProperties: &Schema{Type: "boolean"}},
+			"size":  {OneOf: []*Schema{{Type: "integer"}, {Type: "string", Enum: []any{"small", "large"}}}},
+		},
+		Required: []string{"name"},
+	}
+	op := &Operation{
+		Parameters: []*Parameter{
+			{Name: "id", In: InPath, Required: true, Schema: &Schema{Type: "string", Pattern: "^[0-9]+$"}},
+			{Name: "limit", In: InQuery, Schema: &Schema{Type: "integer", Minimum: ptr(1.0)}},
+			{Name: "verbose", In: InQuery, Schema: &Schema{Type: "boolean"}},
+			{Name: "sort", In: InQuery, Schema: &Schema{Type: "array", Items: &Schema{Type: "string", Enum: []any{"name", "count"}}}},
+			{Name: "q", In: InQuery, Required: true, Schema: &Schema{Type: "string"}},
+			{Name: "fields", In: InQuery, Explode: ptr(false), Schema: &Schema{Type: "array", Items: &Schema{Type: "string", Enum: []any{"name", "count"}}}},
+		},
+		RequestBody: &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{"application/json": {Schema: Ref("Item")}},
+		},
+	}
+	return doc, op
+}
+
+func TestValidateRequest(t *testing.T) {
+	doc, op := validationDoc()
+	valid := Request{
+		PathParams: map[string]string{"id": "42"},
+		Query:      url.Values{"q": {"x"}, "limit": {"5"}, "verbose": {"true"}, "sort": {"name", "count"}, "fields": {"count,name"}, "other": {"ignored"}},
+		Body:       []byte(`{"name":"abc","count":3,"kind":"a","tags":["x"],"meta":{"on":true}}`),
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(r *Request)
+		expected []FieldError
+		err      string
+	}{
+		{name: "valid", modify: func(*Request) {}},
+		{
+			name:     "path pattern",
+			modify:   func(r *Request) { r.PathParams["id"] = "x" },
+			expected: []FieldError{{Field: "id", In: InPath, Message: "must match the pattern ^[0-9]+$"}},
+		},
+		{
+			name:     "missing required query",
+			modify:   func(r *Request) { r.Query.Del("q") },
+			expected: []FieldError{{Field: "q", In: InQuery, Message: "is required"}},
+		},
+		{
+			name:     "query not a number",
+			modify:   func(r *Request) { r.Query.Set("limit", "many") },
+			expected: []FieldError{{Field: "limit", In: InQuery, Message: "must be an integer"}},
+		},
+		{
+			name:     "query fraction",
+			modify:   func(r *Request) { r.Query.Set("limit", "1.5") },
+			expected: []FieldError{{Field: "limit", In: InQuery, Message: "must be an integer"}},
+		},
+		{
+			name:     "query below minimum",
+			modify:   func(r *Request) { r.Query.Set("limit", "0") },
+			expected: []FieldError{{Field: "limit", In: InQuery, Message: "must be at least 1"}},
+		},
+		{
+			name:     "query repeated",
+			modify:   func(r *Request) { r.Query["limit"] = []string{"1", "2"} },
+			expected: []FieldError{{Field: "limit", In: InQuery, Message: "must be given once"}},
+		},
+		{
+			name:     "query boolean",
+			modify:   func(r *Request) { r.Query.Set("verbose", "maybe") },
+			expected: []FieldError{{Field: "verbose", In: InQuery, Message: "must be true or false"}},
+		},
+		{
+			name:     "query array item",
+			modify:   func(r *Request) { r.Query["sort"] = []string{"name", "size"} },
+			expected: []FieldError{{Field: "sort[1]", In: InQuery, Message: `must be one of "name", "count"`}},
+		},
+		{
+			name:     "query comma-separated item",
+			modify:   func(r *Request) { r.Query.Set("fields", "name,size") },
+			expected: []FieldError{{Field: "fields[1]", In: InQuery, Message: `must be one of "name", "count"`}},
+		},
+		{
+			name:   "query comma-separated empty",
+			modify: func(r *Request) { r.Query.Set("fields", "") },
+		},
+		{
+			name:     "query comma-separated repeated",
+			modify:   func(r *Request) { r.Query["fields"] = []string{"name", "count"} },
+			expected: []FieldError{{Field: "fields", In: InQuery, Message: "must be given once"}},
+		},
+		{
+			name:   "body fields",
+			modify: func(r *Request) { r.Body = []byte(`{"count":11,"kind":"c","tags":["x","Y"],"meta":{"on":"yes"}}`) },
+			expected: []FieldError{
+				{Field: "name", In: InBody, Message: "is required"},
+				{Field: "count", In: InBody, Message: "must be at most 10"},
+				{Field: "kind", In: InBody, Message: `must be one of "a", "b"`},
+				{Field: "meta.on", In: InBody, Message: "must be a boolean"},
+				{Field: "tags[1]", In: InBody, Message: "must match the pattern ^[a-z]+$"},
+			},
+		},
+		{
+			name:     "body string length",
+			modify:   func(r *Request) { r.Body = []byte(`{"name":"toolong"}`) },
+			expected: []FieldError{{Field: "name", In: InBody, Message: "must be at most 5 characters"}},
+		},
+		{
+			name:   "body one of",
+			modify: func(r *Request) { r.Body = []byte(`{"name":"a","size":"small"}`) },
+		},
+		{
+			name:     "body none of",
+			modify:   func(r *Request) { r.Body = []byte(`{"name":"a","size":"medium"}`) },
+			expected: []FieldError{{Field: "size", In: InBody, Message: "must match exactly one of 2 schemas, matched 0"}},
+		},
+		{
+			name:     "body array too short",
+			modify:   func(r *Request) { r.Body = []byte(`{"name":"a","tags":[]}`) },
+			expected: []FieldError{{Field: "tags", In: InBody, Message: "must have at least 1 item"}},
+		},
+		{
+			name:     "body array too long",
+			modify:   func(r *Request) { r.Body = []byte(`{"name":"a","tags":["x","y","z"]}`) },
+			expected: []FieldError{{Field: "tags", In: InBody, Message: "must have at most 2 items"}},
+		},
+		{
+			name:     "body wrong type",
+			modify:   func(r *Request) { r.Body = []byte(`[1]`) },
+			expected: []FieldError{{Field: "", In: InBody, Message: "must be an object"}},
+		},
+		{name: "body missing", modify: func(r *Request) { r.Body = nil }, err: "request body is required"},
+		{name: "body malformed", modify: func(r *Request) { r.Body = []byte(`{"name":`) }, err: "not valid JSON"},
+		{name: "body trailing data", modify: func(r *Request) { r.Body = []byte(`{"name":"a"} {}`) }, err: "single JSON value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Request{
+				PathParams: map[string]string{"id": valid.PathParams["id"]},
+				Query:      url.Values{},
+				Body:       valid.Body,
+			}
+			for k, v := range valid.Query {
+				r.Query[k] = append([]string(nil), v...)
+			}
+			tt.modify(&r)
+
+			errs, err := doc.ValidateRequest(op, r)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, errs)
+		})
+	}
+}
+
+func TestValidate_UnknownRef(t *testing.T) {
+	doc := New(Info{})
+	assert.Empty(t, doc.Validate(Ref("Missing"), "anything", ""))
+}
@@ -0,0 +1,164 @@
+// Package problem renders API errors as RFC 9457 problem details.
+package problem
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType is the media type of problem detail responses.
+const ContentType = "application/problem+json"
+
+// TypePrefix prefixes the code of a problem to form its type URI.
+const TypePrefix = "urn:api:problem:"
+
+// requestIDKey is the gin context key under which middleware.RequestID
+// stores the request ID. It is repeated here because middleware depends on
+// this package.
+const requestIDKey = "request_id"
+
+// Code is a stable, machine-readable identifier of a kind of problem.
+// Clients may rely on codes; titles and details are for humans and may
+// change.
+type Code string
+
+// Problem codes returned by the API.
+const (
+	CodeInvalidGUID      Code = "invalid_guid"
+	CodeNotFound         Code = "not_found"
+	CodeInvalidBody      Code = "invalid_body"
+	CodeInvalidRequest   Code = "invalid_request"
+	CodeBodyTooLarge     Code = "body_too_large"
+	CodeInvalidLogLevel  Code = "invalid_log_level"
+	CodeUnauthorized     Code = "unauthorized"
+	CodeAdminDisabled    Code = "admin_disabled"
+	CodeRouteNotFound    Code = "route_not_found"
+	CodeMethodNotAllowed Code = "method_not_allowed"
+	CodeNotAcceptable    Code = "not_acceptable"
+	CodeInternal         Code = "internal_error"
+)
+
+// kind is the fixed status and title of a code.
+type kind struct {
+	status int
+	title  string
+}
+
+var kinds = map[Code]kind{
+	CodeInvalidGUID:      {http.StatusBadRequest, "Invalid GUID format"},
+	CodeNotFound:         {http.StatusNotFound, "Data not found"},
+	CodeInvalidBody:      {http.StatusBadRequest, "Invalid request body"},
+	CodeInvalidRequest:   {http.StatusBadRequest, "Invalid request"},
+	CodeBodyTooLarge:     {http.StatusRequestEntityTooLarge, "Request body too large"},
+	CodeInvalidLogLevel:  {http.StatusBadRequest, "Invalid log level"},
+	CodeUnauthorized:     {http.StatusUnauthorized, "Unauthorized"},
+	CodeAdminDisabled:    {http.StatusForbidden, "Admin endpoints disabled"},
+	CodeRouteNotFound:    {http.StatusNotFound, "Route not found"},
+	CodeMethodNotAllowed: {http.StatusMethodNotAllowed, "Method not allowed"},
+	CodeNotAcceptable:    {http.StatusNotAcceptable, "Not acceptable"},
+	CodeInternal:         {http.StatusInternalServerError, "Internal server error"},
+}
+
+// Codes returns every problem code the API can return.
+func Codes() []Code {
+	return []Code{
+		CodeInvalidGUID,
+		CodeNotFound,
+		CodeInvalidBody,
+		CodeInvalidRequest,
+		CodeBodyTooLarge,
+		CodeInvalidLogLevel,
+		CodeUnauthorized,
+		CodeAdminDisabled,
+		CodeRouteNotFound,
+		CodeMethodNotAllowed,
+		CodeNotAcceptable,
+		CodeInternal,
+	}
+}
+
+// Status returns the HTTP status of code, or 500 for unknown codes.
+func (c Code) Status() int {
+	if k, ok := kinds[c]; ok {
+		return k.status
+	}
+	return http.StatusInternalServerError
+}
+
+// Title returns the human-readable summary of code.
+func (c Code) Title() string {
+	if k, ok := kinds[c]; ok {
+		return k.title
+	}
+	return http.StatusText(http.StatusInternalServerError)
+}
+
+// FieldError describes one invalid request value.
+type FieldError struct {
+	Field   string `json:"field" doc:"Parameter name or dotted path of the body field"`
+	In      string `json:"in" doc:"Where the value was sent: path, query or body"`
+	Message string `json:"message" doc:"What is wrong with the value"`
+	// Position locates the error within the value, for values that are
+	// expressions.
+	Position int `json:"position,omitempty" doc:"1-based character position of the error within the value"`
+}
+
+// Problem is an RFC 9457 problem details object. Code, RequestID and
+// Errors are extension members.
+type Problem struct {
+	Type      string `json:"type" doc:"URI identifying the kind of problem"`
+	Title     string `json:"title" doc:"Short summary of the kind of problem"`
+	Status    int    `json:"status" doc:"HTTP status code"`
+	Detail    string `json:"detail,omitempty" doc:"Explanation of this occurrence"`
+	Instance  string `json:"instance,omitempty" doc:"Path of the request"`
+	Code      Code   `json:"code" doc:"Stable machine-readable error code"`
+	RequestID string `json:"request_id,omitempty" doc:"ID of the request, also sent in X-Request-ID"`
+	// Errors lists the invalid values of an invalid request.
+	Errors []FieldError `json:"errors,omitempty" doc:"Invalid request values"`
+}
+
+// New creates a problem for code. The detail explains this occurrence and
+// may be empty.
+func New(code Code, detail string) *Problem {
+	return &Problem{
+		Type:   TypePrefix + string(code),
+		Title:  code.Title(),
+		Status: code.Status(),
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// Newf creates a problem for code with a formatted detail.
+func Newf(code Code, format string, args ...any) *Problem {
+	return New(code, fmt.Sprintf(format, args...))
+}
+
+// Error implements error.
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+	return p.Title + ": " + p.Detail
+}
+
+// Write renders p as the response, filling in the request path as the
+// instance and the request ID.
+func Write(c *gin.Context, p *Problem) {
+	if p.Instance == "" {
+		p.Instance = c.Request.URL.Path
+	}
+	if p.RequestID == "" {
+		p.RequestID = c.GetString(requestIDKey)
+	}
+	c.Header("Content-Type", ContentType)
+	c.JSON(p.Status, p)
+}
+
+// Abort writes p and stops the remaining handlers.
+func Abort(c *gin.Context, p *Problem) {
+	Write(c, p)
+	c.Abort()
+}
@@ -0,0 +1,66 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	p := New(CodeInvalidGUID, `"x" is not a GUID`)
+
+	assert.Equal(t, "urn:api:problem:invalid_guid", p.Type)
+	assert.Equal(t, "Invalid GUID format", p.Title)
+	assert.Equal(t, http.StatusBadRequest, p.Status)
+	assert.Equal(t, CodeInvalidGUID, p.Code)
+	assert.Equal(t, `Invalid GUID format: "x" is not a GUID`, p.Error())
+	assert.Equal(t, "Data not found", New(CodeNotFound, "").Error())
+}
+
+func TestCodes(t *testing.T) {
+	seen := map[Code]bool{}
+	for _, code := range Codes() {
+		assert.False(t, seen[code], "duplicate code %s", code)
+		seen[code] = true
+		_, ok := kinds[code]
+		assert.True(t, ok, "code %s has no status and title", code)
+	}
+	assert.Len(t, seen, len(kinds))
+
+	assert.Equal(t, http.StatusInternalServerError, Code("bogus").Status())
+}
+
+func TestWrite(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/things/:id", func(c *gin.Context) {
+		c.Set(requestIDKey, "req-1")
+		Abort(c, Newf(CodeNotFound, "no thing %s", c.Param("id")))
+	}, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"unreachable": true})
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/things/42", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, map[string]any{
+		"type":       "urn:api:problem:not_found",
+		"title":      "Data not found",
+		"status":     float64(404),
+		"detail":     "no thing 42",
+		"instance":   "/things/42",
+		"code":       "not_found",
+		"request_id": "req-1",
+	}, body)
+}
@@ -0,0 +1,94 @@
+package render
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Negotiate returns the format in offers that best matches an Accept
+// header: the one with the highest quality, preferring earlier offers on
+// ties. A media range applies to an offer only through its most specific
+// match, so "text/csv;q=0, */*" excludes CSV. An empty header accepts the
+// first offer. It returns false if the client accepts none of the offers.
+func Negotiate(accept string, offers []Format) (Format, bool) {
+	if strings.TrimSpace(accept) == "" {
+		if len(offers) == 0 {
+			return Format{}, false
+		}
+		return offers[0], true
+	}
+	ranges := parseAccept(accept)
+
+	var (
+		best    Format
+		bestQ   float64
+		matched bool
+	)
+	for _, offer := range offers {
+		q := quality(offer.MediaType, ranges)
+		if q > bestQ {
+			best, bestQ, matched = offer, q, true
+		}
+	}
+	return best, matched
+}
+
+// mediaRange is one element of an Accept header.
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// specificity ranks how closely r names a media type: */* < type/* < type/subtype.
+func (r mediaRange) specificity() int {
+	switch {
+	case r.typ == "*":
+		return 0
+	case r.subtype == "*":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// matches reports whether r covers the media type typ/subtype.
+func (r mediaRange) matches(typ, subtype string) bool {
+	return (r.typ == "*" || r.typ == typ) && (r.subtype == "*" || r.subtype == subtype)
+}
+
+// parseAccept parses an Accept header, skipping malformed elements.
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for part := range strings.SplitSeq(accept, ",") {
+		params := strings.Split(part, ";")
+		typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(params[0])), "/")
+		if !ok || typ == "" || subtype == "" || (typ == "*" && subtype != "*") {
+			continue
+		}
+
+		r := mediaRange{typ: typ, subtype: subtype, q: 1}
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				if q, err := strconv.ParseFloat(value, 64); err == nil && q >= 0 && q <= 1 {
+					r.q = q
+				}
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// quality returns the quality the client gives mediaType: that of the
+// most specific matching range, or zero if none matches.
+func quality(mediaType string, ranges []mediaRange) float64 {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		if r.matches(typ, subtype) && r.specificity() > specificity {
+			q, specificity = r.q, r.specificity()
+		}
+	}
+	return q
+}
@@ -0,0 +1,38 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name     string
+		accept   string
+		expected Format
+		ok       bool
+	}{
+		{name: "no header", accept: "", expected: JSON, ok: true},
+		{name: "any", accept: "*/*", expected: JSON, ok: true},
+		{name: "exact", accept: "text/csv", expected: CSV, ok: true},
+		{name: "case insensitive", accept: "Application/YAML", expected: YAML, ok: true},
+		{name: "with parameters", accept: "application/xml; charset=utf-8", expected: XML, ok: true},
+		{name: "type wildcard", accept: "text/*", expected: CSV, ok: true},
+		{name: "highest quality", accept: "application/json;q=0.5, application/x-ndjson", expected: NDJSON, ok: true},
+		{name: "tie prefers server order", accept: "application/yaml, application/xml", expected: XML, ok: true},
+		{name: "browser", accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", expected: XML, ok: true},
+		{name: "specific range excludes", accept: "application/json;q=0, */*", expected: NDJSON, ok: true},
+		{name: "unsupported", accept: "text/html", ok: false},
+		{name: "all excluded", accept: "*/*;q=0", ok: false},
+		{name: "malformed ranges skipped", accept: "garbage, */json, text/csv", expected: CSV, ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, ok := Negotiate(tt.accept, Formats())
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, f)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package render
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// column is a struct field written as a record field or CSV column.
+type column struct {
+	name      string
+	index     int
+	omitempty bool
+}
+
+// columns returns the fields of struct type t in declaration order, named
+// after their JSON keys.
+func columns(t reflect.Type) []column {
+	var cols []column
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		cols = append(cols, column{name: name, index: i, omitempty: strings.Contains(opts, "omitempty")})
+	}
+	return cols
+}
+
+// FieldNames returns the names fields of struct type t can be selected
+// by in Options.Fields, in the order they are written.
+func FieldNames(t reflect.Type) []string {
+	cols := columns(t)
+	names := make([]string, len(cols))
+	for i, col := range cols {
+		names[i] = col.name
+	}
+	return names
+}
+
+// selectColumns returns the columns of t named in fields, in declaration
+// order so output is stable whatever order fields are given in. No fields
+// selects every column.
+func selectColumns(t reflect.Type, fields []string) ([]column, error) {
+	all := columns(t)
+	if len(fields) == 0 {
+		return all, nil
+	}
+
+	for _, name := range fields {
+		if !slices.ContainsFunc(all, func(col column) bool { return col.name == name }) {
+			return nil, fmt.Errorf("unknown field %q (must be one of %s)", name, strings.Join(FieldNames(t), ", "))
+		}
+	}
+	return slices.DeleteFunc(all, func(col column) bool { return !slices.Contains(fields, col.name) }), nil
+}
+
+// Projector returns a function restricting records of type t to fields,
+// for records encoded as part of a larger value. No fields leaves records
+// as they are.
+func Projector(t reflect.Type, fields []string) (func(v any) any, error) {
+	if len(fields) == 0 {
+		return func(v any) any { return v }, nil
+	}
+	st, err := structType(t)
+	if err != nil {
+		return nil, err
+	}
+	cols, err := selectColumns(st, fields)
+	if err != nil {
+		return nil, err
+	}
+	return func(v any) any { return project(v, cols) }, nil
+}
+
+// structType returns the struct type of records of type t.
+func structType(t reflect.Type) (reflect.Type, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot select fields of %s", t)
+	}
+	return t, nil
+}
+
+// projected is a record restricted to some of its fields. It encodes as
+// the record would, without the other fields.
+type projected struct {
+	value reflect.Value
+	cols  []column
+}
+
+// project returns v restricted to cols.
+func project(v any, cols []column) projected {
+	return projected{value: reflect.Indirect(reflect.ValueOf(v)), cols: cols}
+}
+
+// fields calls fn with the name and value of each field to encode,
+// skipping empty omitempty fields.
+func (p projected) fields(fn func(name string, value reflect.Value) error) error {
+	for _, col := range p.cols {
+		fv := p.value.Field(col.index)
+		if col.omitempty && fv.IsZero() {
+			continue
+		}
+		if err := fn(col.name, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (p projected) MarshalJSON() ([]byte, error) {
+	buf := []byte{'{'}
+	err := p.fields(func(name string, value reflect.Value) error {
+		if len(buf) > 1 {
+			buf = append(buf, ',')
+		}
+		key, _ := json.Marshal(name)
+		val, err := json.Marshal(value.Interface())
+		if err != nil {
+			return err
+		}
+		buf = append(append(append(buf, key...), ':'), val...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(buf, '}'), nil
+}
+
+// MarshalXML implements xml.Marshaler.
+func (p projected) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	err := p.fields(func(name string, value reflect.Value) error {
+		return enc.EncodeElement(value.Interface(), xml.StartElement{Name: xml.Name{Local: name}})
+	})
+	if err != nil {
+		return err
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (p projected) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	err := p.fields(func(name string, value reflect.Value) error {
+		var val yaml.Node
+		if err := val.Encode(value.Interface()); err != nil {
+			return err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, &val)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
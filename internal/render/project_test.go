@@ -0,0 +1,123 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldNames(t *testing.T) {
+	assert.Equal(t, []string{"id", "name", "note"}, FieldNames(reflect.TypeFor[record]()))
+}
+
+func TestEncode_Fields(t *testing.T) {
+	// Fields are written in declaration order, whatever order they are
+	// selected in.
+	opts := Options{Item: "record", List: "records", Fields: []string{"note", "id"}}
+
+	tests := []struct {
+		name     string
+		format   Format
+		value    any
+		expected string
+	}{
+		{
+			name:     "json list",
+			format:   JSON,
+			value:    records,
+			expected: `[{"id":"1","note":"first"},{"id":"2"}]` + "\n",
+		},
+		{
+			name:     "json record pointer",
+			format:   JSON,
+			value:    &records[0],
+			expected: `{"id":"1","note":"first"}` + "\n",
+		},
+		{
+			name:     "ndjson list",
+			format:   NDJSON,
+			value:    records,
+			expected: `{"id":"1","note":"first"}` + "\n" + `{"id":"2"}` + "\n",
+		},
+		{
+			name:     "csv list",
+			format:   CSV,
+			value:    records,
+			expected: "id,note\n1,first\n2,\n",
+		},
+		{
+			name:     "xml list",
+			format:   XML,
+			value:    records,
+			expected: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<records><record><id>1</id><note>first</note></record><record><id>2</id></record></records>`,
+		},
+		{
+			name:     "xml record",
+			format:   XML,
+			value:    records[1],
+			expected: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<record><id>2</id></record>`,
+		},
+		{
+			name:     "yaml list",
+			format:   YAML,
+			value:    records,
+			expected: "- id: \"1\"\n  note: first\n- id: \"2\"\n",
+		},
+		{
+			name:     "yaml record",
+			format:   YAML,
+			value:    records[0],
+			expected: "id: \"1\"\nnote: first\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, Encode(&buf, tt.format, tt.value, opts))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestEncode_UnknownField(t *testing.T) {
+	opts := Options{Item: "record", List: "records", Fields: []string{"id", "Secret"}}
+	for _, f := range Formats() {
+		t.Run(f.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Encode(&buf, f, records, opts)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), `unknown field "Secret" (must be one of id, name, note)`)
+			assert.Zero(t, buf.Len(), "nothing is written")
+		})
+	}
+}
+
+func TestProjector(t *testing.T) {
+	type list struct {
+		Records []any `json:"records" xml:"record"`
+	}
+
+	p, err := Projector(reflect.TypeFor[record](), []string{"name"})
+	require.NoError(t, err)
+	v := list{Records: []any{p(records[0]), p(&records[1])}}
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, JSON, v, Options{}))
+	assert.Equal(t, `{"records":[{"name":"Alpha, Inc."},{"name":"Say \"hi\""}]}`+"\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, Encode(&buf, XML, v, Options{Item: "list"}))
+	assert.Equal(t, xml.Header+`<list><record><name>Alpha, Inc.</name></record><record><name>Say &#34;hi&#34;</name></record></list>`, buf.String())
+
+	all, err := Projector(reflect.TypeFor[record](), nil)
+	require.NoError(t, err)
+	assert.Equal(t, records[0], all(records[0]))
+
+	_, err = Projector(reflect.TypeFor[record](), []string{"Secret"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,123 @@
+// Package render negotiates response formats and encodes records in them.
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a response format.
+type Format struct {
+	// Name is the value selecting the format in ?format=.
+	Name string
+	// MediaType is the media type matched against Accept.
+	MediaType string
+}
+
+// Supported formats, in order of preference when a client accepts
+// several equally.
+var (
+	JSON   = Format{Name: "json", MediaType: "application/json"}
+	NDJSON = Format{Name: "ndjson", MediaType: "application/x-ndjson"}
+	CSV    = Format{Name: "csv", MediaType: "text/csv"}
+	XML    = Format{Name: "xml", MediaType: "application/xml"}
+	YAML   = Format{Name: "yaml", MediaType: "application/yaml"}
+)
+
+// Formats returns every supported format in order of preference.
+func Formats() []Format {
+	return []Format{JSON, NDJSON, CSV, XML, YAML}
+}
+
+// ByName returns the format selected by a ?format= value.
+func ByName(name string) (Format, bool) {
+	for _, f := range Formats() {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// ContentType returns the Content-Type header value for responses in f.
+func (f Format) ContentType() string {
+	return f.MediaType + "; charset=utf-8"
+}
+
+// Options control how records are encoded.
+type Options struct {
+	// Item and List are the XML element names of a record and of a list
+	// of records.
+	Item string
+	List string
+	// Fields, if not empty, restricts records to the fields with these
+	// JSON names. Fields are written in declaration order regardless.
+	Fields []string
+}
+
+// Encode writes v in format f. v is a struct, or a slice of structs for a
+// list. Lists are written as a JSON or YAML sequence, one JSON object per
+// line for NDJSON, one CSV row per record after a header row, and item
+// elements within a list element for XML. CSV columns follow the order of
+// the struct fields and are named after their JSON keys. Selecting unknown
+// fields is an error, reported before anything is written.
+func Encode(w io.Writer, f Format, v any, opts Options) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice {
+		items := func(yield func(any) bool) {
+			for i := range rv.Len() {
+				if !yield(rv.Index(i).Interface()) {
+					return
+				}
+			}
+		}
+		_, err := streamList(context.Background(), w, f, rv.Type().Elem(), items, opts, nil)
+		return err
+	}
+
+	if len(opts.Fields) > 0 && f != CSV {
+		t, err := structType(rv.Type())
+		if err != nil {
+			return err
+		}
+		cols, err := selectColumns(t, opts.Fields)
+		if err != nil {
+			return err
+		}
+		v = project(v, cols)
+	}
+
+	switch f {
+	case JSON, NDJSON:
+		return json.NewEncoder(w).Encode(v)
+	case CSV:
+		// A single record is a header row and one data row.
+		_, err := streamList(context.Background(), w, f, rv.Type(), func(yield func(any) bool) { yield(v) }, opts, nil)
+		return err
+	case XML:
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+		enc := xml.NewEncoder(w)
+		if err := enc.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: opts.Item}}); err != nil {
+			return err
+		}
+		return enc.Close()
+	case YAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("unsupported format %q", f.Name)
+	}
+}
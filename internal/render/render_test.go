@@ -0,0 +1,114 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type record struct {
+	ID     string `json:"id" xml:"id" yaml:"id"`
+	Name   string `json:"name" xml:"name" yaml:"name"`
+	Note   string `json:"note,omitempty" xml:"note,omitempty" yaml:"note,omitempty"`
+	Secret string `json:"-" xml:"-" yaml:"-"`
+}
+
+var records = []record{
+	{ID: "1", Name: "Alpha, Inc.", Note: "first"},
+	{ID: "2", Name: `Say "hi"`},
+}
+
+var names = Options{Item: "record", List: "records"}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   Format
+		value    any
+		expected string
+	}{
+		{
+			name:     "json list",
+			format:   JSON,
+			value:    records,
+			expected: `[{"id":"1","name":"Alpha, Inc.","note":"first"},{"id":"2","name":"Say \"hi\""}]` + "\n",
+		},
+		{
+			name:     "ndjson list",
+			format:   NDJSON,
+			value:    records,
+			expected: `{"id":"1","name":"Alpha, Inc.","note":"first"}` + "\n" + `{"id":"2","name":"Say \"hi\""}` + "\n",
+		},
+		{
+			name:     "ndjson record",
+			format:   NDJSON,
+			value:    records[1],
+			expected: `{"id":"2","name":"Say \"hi\""}` + "\n",
+		},
+		{
+			name:     "csv list",
+			format:   CSV,
+			value:    records,
+			expected: "id,name,note\n1,\"Alpha, Inc.\",first\n2,\"Say \"\"hi\"\"\",\n",
+		},
+		{
+			name:     "csv record pointer",
+			format:   CSV,
+			value:    &records[0],
+			expected: "id,name,note\n1,\"Alpha, Inc.\",first\n",
+		},
+		{
+			name:     "csv empty list",
+			format:   CSV,
+			value:    []record{},
+			expected: "id,name,note\n",
+		},
+		{
+			name:   "xml list",
+			format: XML,
+			value:  records,
+			expected: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<records><record><id>1</id><name>Alpha, Inc.</name><note>first</note></record>` +
+				`<record><id>2</id><name>Say &#34;hi&#34;</name></record></records>`,
+		},
+		{
+			name:     "xml record",
+			format:   XML,
+			value:    records[0],
+			expected: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<record><id>1</id><name>Alpha, Inc.</name><note>first</note></record>`,
+		},
+		{
+			name:     "yaml list",
+			format:   YAML,
+			value:    records,
+			expected: "- id: \"1\"\n  name: Alpha, Inc.\n  note: first\n- id: \"2\"\n  name: Say \"hi\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, Encode(&buf, tt.format, tt.value, names))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestEncode_Errors(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Error(t, Encode(&buf, CSV, []string{"a"}, names))
+	assert.Error(t, Encode(&buf, Format{Name: "pdf"}, records, names))
+}
+
+func TestByName(t *testing.T) {
+	for _, f := range Formats() {
+		got, ok := ByName(f.Name)
+		assert.True(t, ok)
+		assert.Equal(t, f, got)
+	}
+	_, ok := ByName("pdf")
+	assert.False(t, ok)
+	assert.Equal(t, "text/csv; charset=utf-8", CSV.ContentType())
+}
@@ -0,0 +1,270 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FlushEvery is how many records Stream writes between flushes.
+const FlushEvery = 100
+
+// streamBufferSize is the size of the buffer Stream writes through, which
+// bounds the memory used by a stream regardless of its length.
+const streamBufferSize = 32 << 10
+
+// Stream writes the records yielded by seq as a list in format f, one at
+// a time, producing the same output as Encode on a slice of them. Every
+// FlushEvery records and at the end it flushes its buffer and then calls
+// flush, if not nil, so clients receive records as they are written.
+//
+// Stream stops when ctx is done or a write fails, e.g. because the client
+// went away or the server's WriteTimeout passed, and returns the error
+// together with the number of records written. The list is then left
+// unterminated, so clients can tell that it is incomplete. Selecting
+// unknown fields is an error, reported before anything is written.
+func Stream[T any](ctx context.Context, w io.Writer, f Format, seq iter.Seq[T], opts Options, flush func()) (int, error) {
+	items := func(yield func(any) bool) {
+		for item := range seq {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+	return streamList(ctx, w, f, reflect.TypeFor[T](), items, opts, flush)
+}
+
+// streamList implements Stream for records of type t.
+func streamList(ctx context.Context, w io.Writer, f Format, t reflect.Type, items iter.Seq[any], opts Options, flush func()) (int, error) {
+	var cols []column
+	if len(opts.Fields) > 0 || f == CSV {
+		st, err := structType(t)
+		if err != nil {
+			return 0, err
+		}
+		if cols, err = selectColumns(st, opts.Fields); err != nil {
+			return 0, err
+		}
+	}
+	if len(opts.Fields) > 0 && f != CSV {
+		all := items
+		items = func(yield func(any) bool) {
+			for item := range all {
+				if !yield(project(item, cols)) {
+					return
+				}
+			}
+		}
+	}
+
+	bw := bufio.NewWriterSize(w, streamBufferSize)
+	lw, err := newListWriter(bw, f, cols, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	flushAll := func() error {
+		if err := lw.flush(); err != nil {
+			return err
+		}
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		if flush != nil {
+			flush()
+		}
+		return nil
+	}
+
+	if err := lw.begin(); err != nil {
+		return 0, err
+	}
+	n := 0
+	for item := range items {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		if err := lw.item(item, n); err != nil {
+			return n, err
+		}
+		n++
+		if n%FlushEvery == 0 {
+			if err := flushAll(); err != nil {
+				return n, err
+			}
+		}
+	}
+	if err := lw.end(n); err != nil {
+		return n, err
+	}
+	return n, flushAll()
+}
+
+// listWriter writes a list in one format.
+type listWriter interface {
+	begin() error
+	// item writes the record at index i.
+	item(v any, i int) error
+	// end terminates a list of n records.
+	end(n int) error
+	// flush writes out anything buffered by the writer itself.
+	flush() error
+}
+
+// newListWriter returns the list writer for format f. CSV lists have the
+// columns cols.
+func newListWriter(w io.Writer, f Format, cols []column, opts Options) (listWriter, error) {
+	switch f {
+	case JSON:
+		return &jsonList{w: w}, nil
+	case NDJSON:
+		return &ndjsonList{enc: json.NewEncoder(w)}, nil
+	case CSV:
+		return &csvList{w: csv.NewWriter(w), columns: cols}, nil
+	case XML:
+		return &xmlList{w: w, enc: xml.NewEncoder(w), opts: opts}, nil
+	case YAML:
+		return &yamlList{w: w}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", f.Name)
+	}
+}
+
+// jsonList writes a JSON array.
+type jsonList struct {
+	w io.Writer
+}
+
+func (l *jsonList) begin() error {
+	_, err := io.WriteString(l.w, "[")
+	return err
+}
+
+func (l *jsonList) item(v any, i int) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if i > 0 {
+		if _, err := io.WriteString(l.w, ","); err != nil {
+			return err
+		}
+	}
+	_, err = l.w.Write(b)
+	return err
+}
+
+func (l *jsonList) end(int) error {
+	_, err := io.WriteString(l.w, "]\n")
+	return err
+}
+
+func (l *jsonList) flush() error { return nil }
+
+// ndjsonList writes one JSON object per line.
+type ndjsonList struct {
+	enc *json.Encoder
+}
+
+func (l *ndjsonList) begin() error            { return nil }
+func (l *ndjsonList) item(v any, _ int) error { return l.enc.Encode(v) }
+func (l *ndjsonList) end(int) error           { return nil }
+func (l *ndjsonList) flush() error            { return nil }
+
+// csvList writes a header row and one row per record.
+type csvList struct {
+	w       *csv.Writer
+	columns []column
+	row     []string
+}
+
+func (l *csvList) begin() error {
+	header := make([]string, len(l.columns))
+	for i, col := range l.columns {
+		header[i] = col.name
+	}
+	l.row = make([]string, len(l.columns))
+	return l.w.Write(header)
+}
+
+func (l *csvList) item(v any, _ int) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	for i, col := range l.columns {
+		l.row[i] = fmt.Sprint(rv.Field(col.index).Interface())
+	}
+	return l.w.Write(l.row)
+}
+
+func (l *csvList) end(int) error { return nil }
+
+func (l *csvList) flush() error {
+	l.w.Flush()
+	return l.w.Error()
+}
+
+// xmlList writes item elements within a list element.
+type xmlList struct {
+	w    io.Writer
+	enc  *xml.Encoder
+	opts Options
+}
+
+func (l *xmlList) begin() error {
+	if _, err := io.WriteString(l.w, xml.Header); err != nil {
+		return err
+	}
+	return l.enc.EncodeToken(xml.StartElement{Name: xml.Name{Local: l.opts.List}})
+}
+
+func (l *xmlList) item(v any, _ int) error {
+	return l.enc.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: l.opts.Item}})
+}
+
+func (l *xmlList) end(int) error {
+	return l.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: l.opts.List}})
+}
+
+func (l *xmlList) flush() error { return l.enc.Flush() }
+
+// yamlList writes a YAML sequence.
+type yamlList struct {
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+func (l *yamlList) begin() error { return nil }
+
+func (l *yamlList) item(v any, _ int) error {
+	// Each record is encoded as a one-element sequence so the entries
+	// concatenate into a single sequence.
+	l.buf.Reset()
+	enc := yaml.NewEncoder(&l.buf)
+	enc.SetIndent(2)
+	if err := enc.Encode([]any{v}); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	_, err := l.w.Write(l.buf.Bytes())
+	return err
+}
+
+func (l *yamlList) end(n int) error {
+	if n > 0 {
+		return nil
+	}
+	_, err := io.WriteString(l.w, "[]\n")
+	return err
+}
+
+func (l *yamlList) flush() error { return nil }
@@ -0,0 +1,106 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func manyRecords(n int) []record {
+	out := make([]record, n)
+	for i := range out {
+		out[i] = record{ID: strconv.Itoa(i), Name: "name " + strconv.Itoa(i)}
+	}
+	return out
+}
+
+func TestStream_MatchesEncode(t *testing.T) {
+	for _, n := range []int{0, 1, FlushEvery + 1} {
+		list := manyRecords(n)
+		for _, f := range Formats() {
+			t.Run(f.Name+"/"+strconv.Itoa(n), func(t *testing.T) {
+				var streamed, encoded bytes.Buffer
+				written, err := Stream(context.Background(), &streamed, f, slices.Values(list), names, nil)
+				require.NoError(t, err)
+				assert.Equal(t, n, written)
+				require.NoError(t, Encode(&encoded, f, list, names))
+				assert.Equal(t, encoded.String(), streamed.String())
+			})
+		}
+	}
+}
+
+func TestStream_Flushes(t *testing.T) {
+	var buf bytes.Buffer
+	var sizes []int
+	flush := func() { sizes = append(sizes, buf.Len()) }
+
+	n, err := Stream(context.Background(), &buf, NDJSON, slices.Values(manyRecords(2*FlushEvery+1)), names, flush)
+	require.NoError(t, err)
+	assert.Equal(t, 2*FlushEvery+1, n)
+
+	// Records reach the writer at every flush, not only at the end.
+	require.Len(t, sizes, 3)
+	assert.Positive(t, sizes[0])
+	assert.Less(t, sizes[0], sizes[1])
+	assert.Equal(t, buf.Len(), sizes[2])
+}
+
+// failingWriter accepts limit bytes and then fails, like a connection
+// whose client went away.
+type failingWriter struct {
+	limit int
+}
+
+var errClosed = errors.New("connection closed")
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		return 0, errClosed
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+func TestStream_StopsOnWriteError(t *testing.T) {
+	yielded := 0
+	seq := func(yield func(record) bool) {
+		for _, r := range manyRecords(10 * FlushEvery) {
+			yielded++
+			if !yield(r) {
+				return
+			}
+		}
+	}
+
+	n, err := Stream(context.Background(), &failingWriter{}, JSON, seq, names, nil)
+	assert.ErrorIs(t, err, errClosed)
+	// Writing stops at the first flush instead of draining the sequence.
+	assert.Equal(t, FlushEvery, n)
+	assert.Equal(t, FlushEvery, yielded)
+}
+
+func TestStream_StopsWhenContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	seq := func(yield func(record) bool) {
+		for i, r := range manyRecords(10) {
+			if i == 3 {
+				cancel()
+			}
+			if !yield(r) {
+				return
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	n, err := Stream(ctx, &buf, JSON, seq, names, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 3, n)
+}
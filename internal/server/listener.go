@@ -0,0 +1,150 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+)
+
+// Listener schemes accepted by ParseListener.
+const (
+	// SchemeTCP serves HTTP/1.1, and HTTP/2 over TLS, on a TCP address.
+	SchemeTCP = "tcp"
+	// SchemeH2C serves cleartext HTTP/2 and HTTP/1.1 on a TCP address.
+	SchemeH2C = "h2c"
+	// SchemeUnix serves HTTP/1.1 on a Unix domain socket.
+	SchemeUnix = "unix"
+	// SchemeH2CUnix serves cleartext HTTP/2 and HTTP/1.1 on a Unix domain socket.
+	SchemeH2CUnix = "h2c+unix"
+)
+
+// ListenerSpec describes one address the server listens on.
+type ListenerSpec struct {
+	// Network is "tcp" or "unix".
+	Network string
+	// Address is a host:port for TCP or a socket path for Unix sockets.
+	Address string
+	// H2C enables cleartext HTTP/2 with prior knowledge.
+	H2C bool
+	// Mode is the permission of a Unix socket file; zero keeps the
+	// default derived from the umask.
+	Mode fs.FileMode
+}
+
+// ParseListener parses a listener spec of the form scheme://address, e.g.
+//
+//	tcp://:3000
+//	h2c://127.0.0.1:8081
+//	unix:///run/api/api.sock?mode=0660
+//	h2c+unix:///run/api/api.sock
+func ParseListener(spec string) (ListenerSpec, error) {
+	u, err := url.Parse(spec)
+	if err != nil || u.Scheme == "" {
+		return ListenerSpec{}, fmt.Errorf("invalid listener %q (must be scheme://address)", spec)
+	}
+
+	var ls ListenerSpec
+	switch u.Scheme {
+	case SchemeTCP, SchemeH2C:
+		ls = ListenerSpec{Network: "tcp", Address: u.Host, H2C: u.Scheme == SchemeH2C}
+		if _, _, err := net.SplitHostPort(ls.Address); err != nil || u.Path != "" {
+			return ListenerSpec{}, fmt.Errorf("invalid listener %q: address must be host:port", spec)
+		}
+	case SchemeUnix, SchemeH2CUnix:
+		ls = ListenerSpec{Network: "unix", Address: u.Host + u.Path, H2C: u.Scheme == SchemeH2CUnix}
+		if ls.Address == "" {
+			return ListenerSpec{}, fmt.Errorf("invalid listener %q: socket path is required", spec)
+		}
+	default:
+		return ListenerSpec{}, fmt.Errorf("invalid listener %q: unknown scheme %q (must be tcp, h2c, unix, or h2c+unix)", spec, u.Scheme)
+	}
+
+	for key, values := range u.Query() {
+		switch {
+		case key == "mode" && ls.Network == "unix":
+			mode, err := strconv.ParseUint(values[0], 8, 32)
+			if err != nil || mode > 0o777 {
+				return ListenerSpec{}, fmt.Errorf("invalid listener %q: invalid mode %q", spec, values[0])
+			}
+			ls.Mode = fs.FileMode(mode)
+		default:
+			return ListenerSpec{}, fmt.Errorf("invalid listener %q: unknown option %q", spec, key)
+		}
+	}
+
+	return ls, nil
+}
+
+// SupportsTLS reports whether the listener serves TLS when it is
+// configured. Only TCP listeners without H2C do; Unix sockets are local
+// and always serve plain HTTP.
+func (s ListenerSpec) SupportsTLS() bool {
+	return s.Network == "tcp" && !s.H2C
+}
+
+// String returns the spec in the form accepted by ParseListener.
+func (s ListenerSpec) String() string {
+	scheme := s.Network
+	if s.H2C {
+		scheme = SchemeH2C
+		if s.Network == "unix" {
+			scheme = SchemeH2CUnix
+		}
+	}
+	spec := scheme + "://" + s.Address
+	if s.Mode != 0 {
+		spec += fmt.Sprintf("?mode=%04o", uint32(s.Mode))
+	}
+	return spec
+}
+
+// Listen opens a listener for spec. A stale socket file left behind by a
+// previous process is removed first; other existing files are an error.
+func Listen(spec ListenerSpec) (net.Listener, error) {
+	if spec.Network == "unix" {
+		if err := removeStaleSocket(spec.Address); err != nil {
+			return nil, err
+		}
+	}
+
+	ln, err := net.Listen(spec.Network, spec.Address)
+	if err != nil {
+		return nil, fmt.Errorf("could not listen on %s: %w", spec, err)
+	}
+
+	if spec.Network == "unix" && spec.Mode != 0 {
+		if err := os.Chmod(spec.Address, spec.Mode); err != nil {
+			ln.Close()
+			return nil, fmt.Errorf("could not set mode of %s: %w", spec.Address, err)
+		}
+	}
+	return ln, nil
+}
+
+// removeStaleSocket removes the socket file at path if nothing accepts
+// connections on it.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not check socket path %s: %w", path, err)
+	}
+	if info.Mode().Type() != fs.ModeSocket {
+		return fmt.Errorf("socket path %s exists and is not a socket", path)
+	}
+
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return fmt.Errorf("socket %s is in use by another process", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("could not remove stale socket %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseListener(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    ListenerSpec
+		wantErr string
+	}{
+		{spec: "tcp://:3000", want: ListenerSpec{Network: "tcp", Address: ":3000"}},
+		{spec: "tcp://127.0.0.1:3000", want: ListenerSpec{Network: "tcp", Address: "127.0.0.1:3000"}},
+		{spec: "h2c://[::1]:8081", want: ListenerSpec{Network: "tcp", Address: "[::1]:8081", H2C: true}},
+		{spec: "unix:///run/api.sock", want: ListenerSpec{Network: "unix", Address: "/run/api.sock"}},
+		{spec: "unix:///run/api.sock?mode=0660", want: ListenerSpec{Network: "unix", Address: "/run/api.sock", Mode: 0o660}},
+		{spec: "h2c+unix://api.sock", want: ListenerSpec{Network: "unix", Address: "api.sock", H2C: true}},
+		{spec: ":3000", wantErr: "must be scheme://address"},
+		{spec: "udp://:3000", wantErr: "unknown scheme"},
+		{spec: "tcp://localhost", wantErr: "address must be host:port"},
+		{spec: "unix://", wantErr: "socket path is required"},
+		{spec: "unix:///run/api.sock?mode=rw", wantErr: "invalid mode"},
+		{spec: "tcp://:3000?mode=0660", wantErr: "unknown option"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseListener(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseListener() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseListener() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseListener() = %+v, want %+v", got, tt.want)
+			}
+
+			// String round-trips
+			again, err := ParseListener(got.String())
+			if err != nil || again != got {
+				t.Errorf("ParseListener(%q) = %+v, %v, want %+v", got.String(), again, err, got)
+			}
+		})
+	}
+}
+
+func TestListen_Unix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.sock")
+	spec := ListenerSpec{Network: "unix", Address: path, Mode: 0o600}
+
+	ln, err := Listen(spec)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("socket mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// A socket in use is not replaced
+	if _, err := Listen(spec); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Errorf("Listen() on a busy socket error = %v, want in use", err)
+	}
+	ln.Close()
+
+	// A stale socket file is replaced
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		t.Fatalf("ListenUnix() error = %v", err)
+	}
+	stale.SetUnlinkOnClose(false)
+	stale.Close()
+
+	ln, err = Listen(spec)
+	if err != nil {
+		t.Fatalf("Listen() over a stale socket error = %v", err)
+	}
+	ln.Close()
+
+	// Regular files are never removed
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := Listen(spec); err == nil || !strings.Contains(err.Error(), "not a socket") {
+		t.Errorf("Listen() over a regular file error = %v, want not a socket", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("regular file was removed: %v", err)
+	}
+}
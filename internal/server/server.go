@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Options configures the HTTP servers behind every listener.
+type Options struct {
+	// Handler serves requests on every listener.
+	Handler      http.Handler
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	// TLSConfig, if set, is used on every listener that supports TLS; see
+	// ListenerSpec.SupportsTLS.
+	TLSConfig *tls.Config
+}
+
+// Listener is an open listener together with the spec it was opened for.
+type Listener struct {
+	net.Listener
+	Spec ListenerSpec
+}
+
+// Server serves one handler, and therefore one middleware chain, on
+// several listeners at once.
+type Server struct {
+	listeners []Listener
+	servers   []*http.Server
+}
+
+// New creates a server for listeners. Each listener gets its own
+// http.Server so that protocols can differ per listener.
+func New(opts Options, listeners []Listener) *Server {
+	s := &Server{listeners: listeners}
+	for _, l := range listeners {
+		srv := &http.Server{
+			Handler:      opts.Handler,
+			ReadTimeout:  opts.ReadTimeout,
+			WriteTimeout: opts.WriteTimeout,
+		}
+		if l.Spec.H2C {
+			srv.Protocols = new(http.Protocols)
+			srv.Protocols.SetHTTP1(true)
+			srv.Protocols.SetUnencryptedHTTP2(true)
+		} else if opts.TLSConfig != nil && l.Spec.SupportsTLS() {
+			srv.TLSConfig = opts.TLSConfig
+		}
+		s.servers = append(s.servers, srv)
+	}
+	return s
+}
+
+// Listeners returns the listeners the server serves on.
+func (s *Server) Listeners() []Listener {
+	return s.listeners
+}
+
+// Serve serves on every listener. It returns the first error from any
+// listener, or nil once all of them have been shut down.
+func (s *Server) Serve() error {
+	errCh := make(chan error, len(s.servers))
+	for i, srv := range s.servers {
+		ln := s.listeners[i].Listener
+		go func() {
+			if srv.TLSConfig != nil {
+				errCh <- srv.ServeTLS(ln, "", "")
+				return
+			}
+			errCh <- srv.Serve(ln)
+		}()
+	}
+
+	for range s.servers {
+		if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Shutdown gracefully shuts down every listener, waiting for in-flight
+// requests until ctx is done. It returns the first error encountered.
+func (s *Server) Shutdown(ctx context.Context) error {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for _, srv := range s.servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := srv.Shutdown(ctx); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
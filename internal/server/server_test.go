@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// protoHandler responds with the request protocol.
+var protoHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	_, _ = io.WriteString(w, r.Proto)
+})
+
+// get requests url with client and returns the response body.
+func get(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("Get(%s) error = %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	return string(body)
+}
+
+func TestServer_MultipleListeners(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", 1, nil)
+	certFile, keyFile := writeCert(t, dir, newTestCert(t, "127.0.0.1", 2, ca))
+	certs, err := NewCertReloader(TLSOptions{CertFile: certFile, KeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("NewCertReloader() error = %v", err)
+	}
+
+	socket := filepath.Join(dir, "api.sock")
+	specs := []ListenerSpec{
+		{Network: "tcp", Address: "127.0.0.1:0"},
+		{Network: "tcp", Address: "127.0.0.1:0", H2C: true},
+		{Network: "unix", Address: socket},
+	}
+	var listeners []Listener
+	for _, spec := range specs {
+		ln, err := Listen(spec)
+		if err != nil {
+			t.Fatalf("Listen(%s) error = %v", spec, err)
+		}
+		listeners = append(listeners, Listener{Listener: ln, Spec: spec})
+	}
+
+	srv := New(Options{Handler: protoHandler, TLSConfig: certs.TLSConfig()}, listeners)
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.Serve() }()
+
+	// TLS on TCP negotiates HTTP/2
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	tlsClient := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: roots},
+		ForceAttemptHTTP2: true,
+	}}
+	if got := get(t, tlsClient, "https://"+listeners[0].Addr().String()); got != "HTTP/2.0" {
+		t.Errorf("TLS listener proto = %s, want HTTP/2.0", got)
+	}
+
+	// h2c serves cleartext HTTP/2 with prior knowledge, and HTTP/1.1
+	h2cTransport := &http.Transport{Protocols: new(http.Protocols)}
+	h2cTransport.Protocols.SetUnencryptedHTTP2(true)
+	if got := get(t, &http.Client{Transport: h2cTransport}, "http://"+listeners[1].Addr().String()); got != "HTTP/2.0" {
+		t.Errorf("h2c listener proto = %s, want HTTP/2.0", got)
+	}
+	if got := get(t, http.DefaultClient, "http://"+listeners[1].Addr().String()); got != "HTTP/1.1" {
+		t.Errorf("h2c listener HTTP/1.1 proto = %s, want HTTP/1.1", got)
+	}
+
+	// Unix sockets serve plain HTTP even with TLS configured
+	unixClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	if got := get(t, unixClient, "http://unix/"); got != "HTTP/1.1" {
+		t.Errorf("unix listener proto = %s, want HTTP/1.1", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Errorf("Serve() error = %v, want nil after Shutdown", err)
+	}
+}
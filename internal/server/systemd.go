@@ -0,0 +1,198 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"api/pkg/logger"
+)
+
+// Environment variables of the systemd socket activation and notification
+// protocols; see sd_listen_fds(3) and sd_notify(3).
+const (
+	listenPIDEnv     = "LISTEN_PID"
+	listenFDsEnv     = "LISTEN_FDS"
+	listenFDNamesEnv = "LISTEN_FDNAMES"
+	notifySocketEnv  = "NOTIFY_SOCKET"
+	watchdogUsecEnv  = "WATCHDOG_USEC"
+	watchdogPIDEnv   = "WATCHDOG_PID"
+
+	// listenFDsStart is the first file descriptor passed by systemd.
+	listenFDsStart = 3
+)
+
+// SystemdListeners returns the sockets passed to the process by systemd
+// socket activation, or nil if there are none. Each socket is matched to
+// the configured spec with the same address to pick up its protocol
+// options; unmatched sockets serve plain HTTP, or h2c if their
+// FileDescriptorName is "h2c".
+func SystemdListeners(configured []ListenerSpec) ([]Listener, error) {
+	return inheritedListeners(os.Getenv, os.Getpid(), listenFDsStart, configured)
+}
+
+// inheritedListeners implements SystemdListeners for the given
+// environment, process ID and first file descriptor.
+func inheritedListeners(getenv func(string) string, pid, firstFD int, configured []ListenerSpec) ([]Listener, error) {
+	if getenv(listenPIDEnv) != strconv.Itoa(pid) {
+		return nil, nil
+	}
+	count, err := strconv.Atoi(getenv(listenFDsEnv))
+	if err != nil || count < 1 {
+		return nil, nil
+	}
+	names := strings.Split(getenv(listenFDNamesEnv), ":")
+
+	listeners := make([]Listener, 0, count)
+	for i := range count {
+		name := "LISTEN_FD_" + strconv.Itoa(firstFD+i)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+
+		// FileListener duplicates the descriptor, so the original is closed.
+		file := os.NewFile(uintptr(firstFD+i), name)
+		ln, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("inherited socket %s is not a listener: %w", name, err)
+		}
+
+		spec, ok := matchSpec(ln.Addr(), configured)
+		if !ok {
+			spec = ListenerSpec{Network: ln.Addr().Network(), Address: ln.Addr().String(), H2C: name == SchemeH2C}
+		}
+		listeners = append(listeners, Listener{Listener: ln, Spec: spec})
+	}
+	return listeners, nil
+}
+
+// matchSpec returns the spec in specs that listens on addr.
+func matchSpec(addr net.Addr, specs []ListenerSpec) (ListenerSpec, bool) {
+	for _, spec := range specs {
+		if spec.Network != addr.Network() {
+			continue
+		}
+		switch a := addr.(type) {
+		case *net.UnixAddr:
+			if spec.Address == a.Name {
+				return spec, true
+			}
+		case *net.TCPAddr:
+			want, err := net.ResolveTCPAddr("tcp", spec.Address)
+			if err != nil || want.Port != a.Port {
+				continue
+			}
+			if want.IP == nil || (want.IP.IsUnspecified() && a.IP.IsUnspecified()) || want.IP.Equal(a.IP) {
+				return spec, true
+			}
+		}
+	}
+	return ListenerSpec{}, false
+}
+
+// Notifier reports the service state to systemd over the socket named by
+// NOTIFY_SOCKET. Without that variable every method is a no-op, so the
+// server runs unchanged outside systemd.
+type Notifier struct {
+	socket   string
+	watchdog time.Duration
+}
+
+// NewNotifier creates a notifier from the environment.
+func NewNotifier() *Notifier {
+	return newNotifier(os.Getenv, os.Getpid())
+}
+
+// newNotifier creates a notifier from the given environment and process ID.
+func newNotifier(getenv func(string) string, pid int) *Notifier {
+	n := &Notifier{socket: getenv(notifySocketEnv)}
+
+	usec, err := strconv.ParseInt(getenv(watchdogUsecEnv), 10, 64)
+	watchdogPID := getenv(watchdogPIDEnv)
+	if err == nil && usec > 0 && (watchdogPID == "" || watchdogPID == strconv.Itoa(pid)) {
+		n.watchdog = time.Duration(usec) * time.Microsecond
+	}
+	return n
+}
+
+// Enabled reports whether notifications are sent.
+func (n *Notifier) Enabled() bool {
+	return n.socket != ""
+}
+
+// Notify sends newline-separated state assignments such as "READY=1".
+func (n *Notifier) Notify(states ...string) error {
+	if !n.Enabled() {
+		return nil
+	}
+
+	// A leading "@" names a socket in the Linux abstract namespace.
+	name := n.socket
+	if strings.HasPrefix(name, "@") {
+		name = "\x00" + name[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: name, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("could not connect to notify socket: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(strings.Join(states, "\n"))); err != nil {
+		return fmt.Errorf("could not send notification: %w", err)
+	}
+	return nil
+}
+
+// Ready reports that startup or a reload has finished.
+func (n *Notifier) Ready() error {
+	return n.Notify("READY=1", "MAINPID="+strconv.Itoa(os.Getpid()))
+}
+
+// Reloading reports that the configuration is being reloaded.
+func (n *Notifier) Reloading() error {
+	return n.Notify("RELOADING=1")
+}
+
+// Stopping reports that the server is shutting down.
+func (n *Notifier) Stopping() error {
+	return n.Notify("STOPPING=1")
+}
+
+// WatchdogInterval returns the watchdog timeout requested by systemd with
+// WATCHDOG_USEC, or zero if the watchdog is disabled.
+func (n *Notifier) WatchdogInterval() time.Duration {
+	if !n.Enabled() {
+		return 0
+	}
+	return n.watchdog
+}
+
+// Watchdog sends keep-alive pings at half the watchdog interval until ctx
+// is done. It returns immediately if the watchdog is disabled.
+func (n *Notifier) Watchdog(ctx context.Context) {
+	interval := n.WatchdogInterval() / 2
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := n.Notify("WATCHDOG=1"); err != nil {
+				logger.Component(ctx, "server").Warn("Watchdog notification failed", "error", err)
+			}
+		}
+	}
+}
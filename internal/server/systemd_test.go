@@ -0,0 +1,188 @@
+package server
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// envMap returns a getenv function backed by env.
+func envMap(env map[string]string) func(string) string {
+	return func(key string) string { return env[key] }
+}
+
+// listenNotifySocket opens a notify socket and returns its path and a
+// function that receives the next message.
+func listenNotifySocket(t *testing.T) (string, func() string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("ListenUnixgram() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return path, func() string {
+		t.Helper()
+		buf := make([]byte, 4096)
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatalf("Read() from notify socket error = %v", err)
+		}
+		return string(buf[:n])
+	}
+}
+
+func TestNotifier(t *testing.T) {
+	socket, receive := listenNotifySocket(t)
+	n := newNotifier(envMap(map[string]string{notifySocketEnv: socket}), 1)
+
+	if !n.Enabled() {
+		t.Fatal("Enabled() = false with NOTIFY_SOCKET set")
+	}
+
+	tests := []struct {
+		name   string
+		notify func() error
+		want   string
+	}{
+		{name: "ready", notify: n.Ready, want: "READY=1\nMAINPID="},
+		{name: "reloading", notify: n.Reloading, want: "RELOADING=1"},
+		{name: "stopping", notify: n.Stopping, want: "STOPPING=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.notify(); err != nil {
+				t.Fatalf("notify error = %v", err)
+			}
+			if got := receive(); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("message = %q, want prefix %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifier_Disabled(t *testing.T) {
+	n := newNotifier(envMap(map[string]string{watchdogUsecEnv: "1000000"}), 1)
+	if n.Enabled() || n.WatchdogInterval() != 0 {
+		t.Error("notifier without NOTIFY_SOCKET should be disabled")
+	}
+	if err := n.Ready(); err != nil {
+		t.Errorf("Ready() error = %v, want no-op", err)
+	}
+}
+
+func TestNotifier_Watchdog(t *testing.T) {
+	socket, receive := listenNotifySocket(t)
+
+	other := newNotifier(envMap(map[string]string{
+		notifySocketEnv: socket,
+		watchdogUsecEnv: "20000",
+		watchdogPIDEnv:  "2",
+	}), 1)
+	if other.WatchdogInterval() != 0 {
+		t.Error("WatchdogInterval() should be zero when WATCHDOG_PID names another process")
+	}
+
+	n := newNotifier(envMap(map[string]string{
+		notifySocketEnv: socket,
+		watchdogUsecEnv: "20000",
+		watchdogPIDEnv:  "1",
+	}), 1)
+	if got := n.WatchdogInterval(); got != 20*time.Millisecond {
+		t.Fatalf("WatchdogInterval() = %v, want 20ms", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go n.Watchdog(ctx)
+
+	for range 2 {
+		if got := receive(); got != "WATCHDOG=1" {
+			t.Errorf("message = %q, want WATCHDOG=1", got)
+		}
+	}
+}
+
+func TestInheritedListeners(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer ln.Close()
+
+	// Pass a duplicate of the socket as if systemd had, named "h2c".
+	file, err := ln.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatalf("File() error = %v", err)
+	}
+	env := map[string]string{
+		listenPIDEnv:     "42",
+		listenFDsEnv:     "1",
+		listenFDNamesEnv: "h2c",
+	}
+
+	if got, err := inheritedListeners(envMap(env), 7, int(file.Fd()), nil); err != nil || got != nil {
+		t.Errorf("inheritedListeners() for another PID = %v, %v, want none", got, err)
+	}
+
+	listeners, err := inheritedListeners(envMap(env), 42, int(file.Fd()), nil)
+	if err != nil {
+		t.Fatalf("inheritedListeners() error = %v", err)
+	}
+	if len(listeners) != 1 {
+		t.Fatalf("inheritedListeners() = %v, want 1 listener", listeners)
+	}
+	defer listeners[0].Close()
+
+	spec := listeners[0].Spec
+	if spec.Network != "tcp" || spec.Address != ln.Addr().String() || !spec.H2C {
+		t.Errorf("Spec = %+v, want h2c on %s", spec, ln.Addr())
+	}
+
+	// The inherited socket accepts connections
+	go func() {
+		if conn, err := net.Dial("tcp", ln.Addr().String()); err == nil {
+			conn.Close()
+		}
+	}()
+	conn, err := listeners[0].Accept()
+	if err != nil {
+		t.Fatalf("Accept() error = %v", err)
+	}
+	conn.Close()
+}
+
+func TestMatchSpec(t *testing.T) {
+	specs := []ListenerSpec{
+		{Network: "tcp", Address: ":8081", H2C: true},
+		{Network: "tcp", Address: "127.0.0.1:3000"},
+		{Network: "unix", Address: "/run/api.sock", Mode: 0o660},
+	}
+
+	tests := []struct {
+		addr net.Addr
+		want string
+	}{
+		{addr: &net.TCPAddr{IP: net.IPv6unspecified, Port: 8081}, want: specs[0].String()},
+		{addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 3000}, want: specs[1].String()},
+		{addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 3000}},
+		{addr: &net.UnixAddr{Name: "/run/api.sock", Net: "unix"}, want: specs[2].String()},
+	}
+	for _, tt := range tests {
+		got, ok := matchSpec(tt.addr, specs)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("matchSpec(%v) = %v, want no match", tt.addr, got)
+			}
+			continue
+		}
+		if !ok || got.String() != tt.want {
+			t.Errorf("matchSpec(%v) = %v, %v, want %s", tt.addr, got, ok, tt.want)
+		}
+	}
+}
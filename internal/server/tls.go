@@ -0,0 +1,267 @@
+// Package server provides the network plumbing of the HTTP server, such as
+// TLS with certificate hot-reload.
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"api/pkg/logger"
+)
+
+// Client certificate policies accepted by TLSOptions.ClientAuth.
+const (
+	// ClientAuthNone does not ask for client certificates.
+	ClientAuthNone = "none"
+	// ClientAuthOptional verifies client certificates that are presented.
+	ClientAuthOptional = "optional"
+	// ClientAuthRequire requires a valid client certificate.
+	ClientAuthRequire = "require"
+)
+
+// TLSOptions configures TLS serving.
+type TLSOptions struct {
+	// CertFile and KeyFile are the PEM-encoded certificate chain and key.
+	CertFile string
+	KeyFile  string
+	// MinVersion is the lowest TLS version accepted, "1.2" or "1.3".
+	// Empty means 1.2.
+	MinVersion string
+	// CipherSuites restricts the TLS 1.2 cipher suites, by their standard
+	// names (e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256). Empty uses Go's
+	// secure defaults. TLS 1.3 suites are not configurable.
+	CipherSuites []string
+	// ClientCAFile is a PEM bundle of CAs that client certificates are
+	// verified against.
+	ClientCAFile string
+	// ClientAuth is ClientAuthNone, ClientAuthOptional or ClientAuthRequire.
+	// Empty means ClientAuthNone.
+	ClientAuth string
+}
+
+// ParseTLSVersion parses a minimum TLS version such as "1.3".
+func ParseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %q (must be 1.2 or 1.3)", version)
+	}
+}
+
+// ParseCipherSuites resolves cipher suite names to their IDs. Suites with
+// known security issues are rejected.
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	available := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		available[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := available[strings.ToUpper(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ParseClientAuth parses a client certificate policy.
+func ParseClientAuth(policy string) (tls.ClientAuthType, error) {
+	switch policy {
+	case "", ClientAuthNone:
+		return tls.NoClientCert, nil
+	case ClientAuthOptional:
+		return tls.VerifyClientCertIfGiven, nil
+	case ClientAuthRequire:
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return 0, fmt.Errorf("invalid client auth %q (must be none, optional, or require)", policy)
+	}
+}
+
+// tlsState is the material loaded from the certificate files.
+type tlsState struct {
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  []time.Time
+}
+
+// CertReloader serves TLS with a certificate, key and client CA bundle
+// that are re-read when the files change. Reloading only affects new
+// handshakes, so established connections are never dropped.
+type CertReloader struct {
+	opts  TLSOptions
+	base  *tls.Config
+	mu    sync.Mutex
+	state atomic.Pointer[tlsState]
+}
+
+// NewCertReloader validates opts and loads the certificate files.
+func NewCertReloader(opts TLSOptions) (*CertReloader, error) {
+	if opts.CertFile == "" || opts.KeyFile == "" {
+		return nil, errors.New("TLS requires both a certificate and a key file")
+	}
+	minVersion, err := ParseTLSVersion(opts.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	cipherSuites, err := ParseCipherSuites(opts.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	clientAuth, err := ParseClientAuth(opts.ClientAuth)
+	if err != nil {
+		return nil, err
+	}
+	if clientAuth != tls.NoClientCert && opts.ClientCAFile == "" {
+		return nil, errors.New("client certificate verification requires a client CA file")
+	}
+
+	r := &CertReloader{
+		opts: opts,
+		base: &tls.Config{
+			MinVersion:   minVersion,
+			CipherSuites: cipherSuites,
+			ClientAuth:   clientAuth,
+			NextProtos:   []string{"h2", "http/1.1"},
+		},
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// files returns the files the TLS material is loaded from.
+func (r *CertReloader) files() []string {
+	files := []string{r.opts.CertFile, r.opts.KeyFile}
+	if r.opts.ClientCAFile != "" {
+		files = append(files, r.opts.ClientCAFile)
+	}
+	return files
+}
+
+// Reload re-reads the certificate files. On error the previously loaded
+// material stays in use.
+func (r *CertReloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Record modification times first so a change made while loading is
+	// picked up by the next check.
+	modTimes := fileModTimes(r.files())
+
+	cert, err := tls.LoadX509KeyPair(r.opts.CertFile, r.opts.KeyFile)
+	if err != nil {
+		return fmt.Errorf("could not load TLS certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.opts.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.opts.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("could not read client CA file: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("client CA file %s contains no certificates", r.opts.ClientCAFile)
+		}
+	}
+
+	r.state.Store(&tlsState{cert: &cert, clientCAs: clientCAs, modTimes: modTimes})
+	return nil
+}
+
+// Certificate returns the certificate currently served.
+func (r *CertReloader) Certificate() *tls.Certificate {
+	return r.state.Load().cert
+}
+
+// TLSConfig returns a TLS configuration that always uses the most recently
+// loaded certificate and client CAs.
+func (r *CertReloader) TLSConfig() *tls.Config {
+	cfg := r.base.Clone()
+	cfg.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return r.Certificate(), nil
+	}
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		state := r.state.Load()
+		perConn := r.base.Clone()
+		perConn.Certificates = []tls.Certificate{*state.cert}
+		perConn.ClientCAs = state.clientCAs
+		return perConn, nil
+	}
+	return cfg
+}
+
+// changed reports whether any certificate file was modified since it was
+// last loaded.
+func (r *CertReloader) changed() bool {
+	loaded := r.state.Load().modTimes
+	for i, mod := range fileModTimes(r.files()) {
+		if !mod.IsZero() && !mod.Equal(loaded[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch reloads the certificate files whenever they change, checking
+// every interval, and logs the outcome. A failed reload keeps the current
+// certificate, for example while a rotation has replaced only one of the
+// files. Watch blocks until ctx is done; a non-positive interval disables
+// it.
+func (r *CertReloader) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			// The log outputs may have been replaced since the last tick.
+			log := logger.Component(ctx, "server")
+			if err := r.Reload(); err != nil {
+				log.Warn("TLS certificate reload failed; keeping current certificate", "error", err)
+				continue
+			}
+			log.Info("TLS certificate reloaded", "not_after", r.Certificate().Leaf.NotAfter)
+		}
+	}
+}
+
+// fileModTimes returns the modification time of each path, or the zero
+// time for files that cannot be read.
+func fileModTimes(paths []string) []time.Time {
+	times := make([]time.Time, len(paths))
+	for i, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			times[i] = info.ModTime()
+		}
+	}
+	return times
+}
@@ -0,0 +1,268 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCert is a generated certificate with its key.
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCert creates a certificate for name signed by parent, or a
+// self-signed CA if parent is nil.
+func newTestCert(t *testing.T, name string, serial int64, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate() error = %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey() error = %v", err)
+	}
+
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// tlsPair returns a client certificate for use with tls.Config.
+func (c *testCert) tlsPair(t *testing.T) tls.Certificate {
+	t.Helper()
+	pair, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	if err != nil {
+		t.Fatalf("X509KeyPair() error = %v", err)
+	}
+	return pair
+}
+
+// writeCert writes the certificate and key to cert.pem and key.pem in dir.
+func writeCert(t *testing.T, dir string, c *testCert) (certFile, keyFile string) {
+	t.Helper()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, c.certPEM, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.WriteFile(keyFile, c.keyPEM, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return certFile, keyFile
+}
+
+// touch moves the modification time of path forward so a change is seen
+// on filesystems with coarse timestamps.
+func touch(t *testing.T, path string) {
+	t.Helper()
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+}
+
+func TestParseTLSOptions(t *testing.T) {
+	if v, err := ParseTLSVersion("1.3"); err != nil || v != tls.VersionTLS13 {
+		t.Errorf("ParseTLSVersion(1.3) = %v, %v", v, err)
+	}
+	if v, err := ParseTLSVersion(""); err != nil || v != tls.VersionTLS12 {
+		t.Errorf("ParseTLSVersion(\"\") = %v, %v, want TLS 1.2", v, err)
+	}
+	if _, err := ParseTLSVersion("1.0"); err == nil {
+		t.Error("ParseTLSVersion(1.0) expected error")
+	}
+
+	ids, err := ParseCipherSuites([]string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"})
+	if err != nil || len(ids) != 1 || ids[0] != tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256 {
+		t.Errorf("ParseCipherSuites() = %v, %v", ids, err)
+	}
+	if _, err := ParseCipherSuites([]string{"TLS_RSA_WITH_RC4_128_SHA"}); err == nil {
+		t.Error("ParseCipherSuites() should reject insecure suites")
+	}
+
+	if auth, err := ParseClientAuth(ClientAuthRequire); err != nil || auth != tls.RequireAndVerifyClientCert {
+		t.Errorf("ParseClientAuth(require) = %v, %v", auth, err)
+	}
+	if _, err := ParseClientAuth("always"); err == nil {
+		t.Error("ParseClientAuth(always) expected error")
+	}
+}
+
+func TestNewCertReloader_Errors(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir, newTestCert(t, "localhost", 1, nil))
+
+	tests := []struct {
+		name    string
+		opts    TLSOptions
+		wantErr string
+	}{
+		{name: "missing key", opts: TLSOptions{CertFile: certFile}, wantErr: "both a certificate and a key"},
+		{name: "unreadable cert", opts: TLSOptions{CertFile: filepath.Join(dir, "missing.pem"), KeyFile: keyFile}, wantErr: "could not load TLS certificate"},
+		{name: "client auth without CA", opts: TLSOptions{CertFile: certFile, KeyFile: keyFile, ClientAuth: ClientAuthRequire}, wantErr: "requires a client CA file"},
+		{name: "CA without certificates", opts: TLSOptions{CertFile: certFile, KeyFile: keyFile, ClientCAFile: keyFile, ClientAuth: ClientAuthRequire}, wantErr: "contains no certificates"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCertReloader(tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewCertReloader() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCertReloader_Watch(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", 1, nil)
+	certFile, keyFile := writeCert(t, dir, newTestCert(t, "localhost", 2, ca))
+
+	r, err := NewCertReloader(TLSOptions{CertFile: certFile, KeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("NewCertReloader() error = %v", err)
+	}
+	served := func() int64 {
+		cfg, err := r.TLSConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+		if err != nil {
+			t.Fatalf("GetConfigForClient() error = %v", err)
+		}
+		return cfg.Certificates[0].Leaf.SerialNumber.Int64()
+	}
+	if got := served(); got != 2 {
+		t.Fatalf("served serial = %d, want 2", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Watch(ctx, 10*time.Millisecond)
+
+	writeCert(t, dir, newTestCert(t, "localhost", 3, ca))
+	touch(t, certFile)
+	touch(t, keyFile)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for served() != 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("Watch() did not reload the rotated certificate")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// A broken rotation keeps the current certificate.
+	if err := os.WriteFile(keyFile, []byte("not a key"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := r.Reload(); err == nil {
+		t.Error("Reload() expected error for a malformed key")
+	}
+	if got := served(); got != 3 {
+		t.Errorf("served serial = %d after failed reload, want 3", got)
+	}
+}
+
+func TestCertReloader_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", 1, nil)
+	certFile, keyFile := writeCert(t, dir, newTestCert(t, "127.0.0.1", 2, ca))
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caFile, ca.certPEM, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	r, err := NewCertReloader(TLSOptions{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		MinVersion:   "1.3",
+		ClientCAFile: caFile,
+		ClientAuth:   ClientAuthRequire,
+	})
+	if err != nil {
+		t.Fatalf("NewCertReloader() error = %v", err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(req.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	srv.TLS = r.TLSConfig()
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	client := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			Certificates: certs,
+		}}}
+	}
+
+	resp, err := client(newTestCert(t, "client", 3, ca).tlsPair(t)).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get() with client certificate error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.TLS.Version != tls.VersionTLS13 {
+		t.Errorf("negotiated version = %x, want TLS 1.3", resp.TLS.Version)
+	}
+
+	if resp, err := client().Get(srv.URL); err == nil {
+		resp.Body.Close()
+		t.Error("Get() without client certificate should fail")
+	}
+
+	untrusted := newTestCert(t, "other-ca", 4, nil)
+	if resp, err := client(newTestCert(t, "client", 5, untrusted).tlsPair(t)).Get(srv.URL); err == nil {
+		resp.Body.Close()
+		t.Error("Get() with an untrusted client certificate should fail")
+	}
+}
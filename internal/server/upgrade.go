@@ -0,0 +1,217 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables passed by Upgrade to the new process.
+const (
+	// upgradeListenersEnv lists the specs of the inherited listeners, in
+	// file descriptor order starting at listenFDsStart.
+	upgradeListenersEnv = "UPGRADE_LISTENERS"
+	// upgradeReadyFDEnv names the descriptor the new process writes to
+	// once it is serving.
+	upgradeReadyFDEnv = "UPGRADE_READY_FD"
+)
+
+// filer is implemented by listeners whose socket can be passed to another
+// process, i.e. *net.TCPListener and *net.UnixListener.
+type filer interface {
+	File() (*os.File, error)
+}
+
+// Upgrade starts a new instance of the running executable with the same
+// arguments and environment, handing it the listeners, and waits up to
+// timeout for it to report that it is serving. On success the caller
+// should stop accepting connections, drain in-flight requests and exit;
+// Unix socket files are left in place for the new process. On failure the
+// new process is killed and the caller keeps serving.
+func Upgrade(listeners []Listener, timeout time.Duration) (*os.Process, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("could not find executable: %w", err)
+	}
+
+	files := make([]*os.File, 0, len(listeners)+1)
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+
+	specs := make([]string, 0, len(listeners))
+	for _, l := range listeners {
+		fl, ok := l.Listener.(filer)
+		if !ok {
+			return nil, fmt.Errorf("listener %s cannot be handed over", l.Spec)
+		}
+		f, err := fl.File()
+		if err != nil {
+			return nil, fmt.Errorf("could not hand over listener %s: %w", l.Spec, err)
+		}
+		files = append(files, f)
+		specs = append(specs, l.Spec.String())
+	}
+
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("could not create readiness pipe: %w", err)
+	}
+	defer readyR.Close()
+	files = append(files, readyW)
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(handoffEnviron(os.Environ()),
+		upgradeListenersEnv+"="+strings.Join(specs, ","),
+		upgradeReadyFDEnv+"="+strconv.Itoa(listenFDsStart+len(specs)),
+	)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("could not start new process: %w", err)
+	}
+
+	// Close our copy of the write end so that the read fails as soon as the
+	// new process exits without reporting ready.
+	readyW.Close()
+	files = files[:len(files)-1]
+
+	if err := waitReady(readyR, timeout); err != nil {
+		_ = cmd.Process.Kill()
+		go func() { _ = cmd.Wait() }()
+		return nil, err
+	}
+
+	for _, l := range listeners {
+		if ul, ok := l.Listener.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+	}
+	// Reap the new process should it exit before this one.
+	go func() { _ = cmd.Wait() }()
+	return cmd.Process, nil
+}
+
+// waitReady waits for the new process to write to the readiness pipe.
+func waitReady(r *os.File, timeout time.Duration) error {
+	if err := r.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return fmt.Errorf("could not wait for new process: %w", err)
+	}
+	buf := make([]byte, 1)
+	_, err := r.Read(buf)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		return fmt.Errorf("new process did not become ready within %s", timeout)
+	case errors.Is(err, io.EOF):
+		return errors.New("new process exited before becoming ready")
+	default:
+		return fmt.Errorf("could not wait for new process: %w", err)
+	}
+}
+
+// handoffEnviron returns env without the variables describing inherited
+// sockets, which are not valid in the new process, and without
+// WATCHDOG_PID, so that the new process takes over the watchdog pings
+// from this one.
+func handoffEnviron(env []string) []string {
+	filtered := make([]string, 0, len(env))
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		switch name {
+		case upgradeListenersEnv, upgradeReadyFDEnv, listenPIDEnv, listenFDsEnv, listenFDNamesEnv, watchdogPIDEnv:
+			continue
+		}
+		filtered = append(filtered, kv)
+	}
+	return filtered
+}
+
+// Handoff is what a process started by Upgrade inherits from its parent.
+type Handoff struct {
+	// Listeners are the parent's listeners, already accepting connections.
+	Listeners []Listener
+	ready     *os.File
+}
+
+// InheritHandoff returns the listeners handed over by Upgrade, or nil if
+// the process was not started by Upgrade.
+func InheritHandoff() (*Handoff, error) {
+	h, err := inheritHandoff(os.Getenv, listenFDsStart)
+	os.Unsetenv(upgradeListenersEnv)
+	os.Unsetenv(upgradeReadyFDEnv)
+	return h, err
+}
+
+// inheritHandoff implements InheritHandoff for the given environment and
+// first file descriptor.
+func inheritHandoff(getenv func(string) string, firstFD int) (*Handoff, error) {
+	list := getenv(upgradeListenersEnv)
+	if list == "" {
+		return nil, nil
+	}
+
+	h := &Handoff{}
+	for i, raw := range strings.Split(list, ",") {
+		spec, err := ParseListener(raw)
+		if err != nil {
+			h.Close()
+			return nil, fmt.Errorf("invalid inherited listener: %w", err)
+		}
+
+		// FileListener duplicates the descriptor, so the original is closed.
+		file := os.NewFile(uintptr(firstFD+i), raw)
+		ln, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			h.Close()
+			return nil, fmt.Errorf("inherited socket %s is not a listener: %w", raw, err)
+		}
+		// This process now owns the socket file and removes it on shutdown.
+		if ul, ok := ln.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(true)
+		}
+		h.Listeners = append(h.Listeners, Listener{Listener: ln, Spec: spec})
+	}
+
+	if fd, err := strconv.Atoi(getenv(upgradeReadyFDEnv)); err == nil {
+		h.ready = os.NewFile(uintptr(fd), "upgrade-ready")
+	}
+	return h, nil
+}
+
+// Ready tells the parent process that this process is serving, so the
+// parent can stop accepting connections and drain.
+func (h *Handoff) Ready() error {
+	if h.ready == nil {
+		return nil
+	}
+	defer func() {
+		h.ready.Close()
+		h.ready = nil
+	}()
+	if _, err := h.ready.Write([]byte{1}); err != nil {
+		return fmt.Errorf("could not notify parent process: %w", err)
+	}
+	return nil
+}
+
+// Close closes the inherited listeners and the readiness descriptor. It is
+// only needed if the handoff is abandoned.
+func (h *Handoff) Close() {
+	for _, l := range h.Listeners {
+		l.Close()
+	}
+	if h.ready != nil {
+		h.ready.Close()
+	}
+}
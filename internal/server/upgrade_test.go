@@ -0,0 +1,136 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// upgradeChildEnv makes the test binary act as the new process started by
+// Upgrade: "serve" serves and reports ready, "fail" exits immediately.
+const upgradeChildEnv = "SERVER_TEST_UPGRADE_CHILD"
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(upgradeChildEnv) {
+	case "serve":
+		runUpgradeChild()
+	case "fail":
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// runUpgradeChild serves "child" on the inherited listeners until killed.
+func runUpgradeChild() {
+	h, err := InheritHandoff()
+	if err != nil || h == nil {
+		os.Exit(2)
+	}
+	srv := New(Options{Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "child")
+	})}, h.Listeners)
+	go func() { _ = srv.Serve() }()
+
+	// Like the server, take over from the parent as the service's main
+	// process before telling the parent to stop.
+	n := NewNotifier()
+	if n.WatchdogInterval() == 0 {
+		os.Exit(4)
+	}
+	if err := n.Ready(); err != nil {
+		os.Exit(5)
+	}
+	if err := h.Ready(); err != nil {
+		os.Exit(3)
+	}
+	select {}
+}
+
+func TestUpgrade(t *testing.T) {
+	t.Setenv(upgradeChildEnv, "serve")
+	notifySocket, receive := listenNotifySocket(t)
+	t.Setenv(notifySocketEnv, notifySocket)
+	t.Setenv(watchdogUsecEnv, "30000000")
+	t.Setenv(watchdogPIDEnv, strconv.Itoa(os.Getpid()))
+
+	socket := filepath.Join(t.TempDir(), "api.sock")
+	var listeners []Listener
+	for _, spec := range []ListenerSpec{
+		{Network: "tcp", Address: "127.0.0.1:0"},
+		{Network: "unix", Address: socket},
+	} {
+		ln, err := Listen(spec)
+		if err != nil {
+			t.Fatalf("Listen() error = %v", err)
+		}
+		listeners = append(listeners, Listener{Listener: ln, Spec: spec})
+	}
+	addr := listeners[0].Addr().String()
+
+	proc, err := Upgrade(listeners, 10*time.Second)
+	if err != nil {
+		t.Fatalf("Upgrade() error = %v", err)
+	}
+	defer func() { _ = proc.Kill() }()
+
+	// The new process runs the watchdog and reported itself as the main
+	// process before Upgrade returned.
+	if got, want := receive(), "READY=1\nMAINPID="+strconv.Itoa(proc.Pid); got != want {
+		t.Errorf("notification from new process = %q, want %q", got, want)
+	}
+
+	// The old process stops accepting; the socket file must survive.
+	for _, l := range listeners {
+		l.Close()
+	}
+	if _, err := os.Stat(socket); err != nil {
+		t.Errorf("socket file removed by the old process: %v", err)
+	}
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	if got := get(t, client, "http://"+addr); got != "child" {
+		t.Errorf("response after upgrade = %q, want served by the new process", got)
+	}
+}
+
+func TestUpgrade_ChildFails(t *testing.T) {
+	t.Setenv(upgradeChildEnv, "fail")
+
+	ln, err := Listen(ListenerSpec{Network: "tcp", Address: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer ln.Close()
+
+	_, err = Upgrade([]Listener{{Listener: ln, Spec: ListenerSpec{Network: "tcp", Address: "127.0.0.1:0"}}}, 10*time.Second)
+	if err == nil || !strings.Contains(err.Error(), "exited before becoming ready") {
+		t.Errorf("Upgrade() error = %v, want child exit reported", err)
+	}
+}
+
+func TestHandoffEnviron(t *testing.T) {
+	env := handoffEnviron([]string{
+		"PATH=/bin",
+		"LISTEN_PID=1",
+		"LISTEN_FDS=2",
+		"UPGRADE_LISTENERS=tcp://:3000",
+		"NOTIFY_SOCKET=/run/notify",
+		"WATCHDOG_USEC=30000000",
+		"WATCHDOG_PID=1",
+	})
+	if got := strings.Join(env, " "); got != "PATH=/bin NOTIFY_SOCKET=/run/notify WATCHDOG_USEC=30000000" {
+		t.Errorf("handoffEnviron() = %s", got)
+	}
+}
+
+func TestInheritHandoff_None(t *testing.T) {
+	h, err := inheritHandoff(envMap(nil), listenFDsStart)
+	if h != nil || err != nil {
+		t.Errorf("inheritHandoff() = %v, %v, want nil without a handoff", h, err)
+	}
+}
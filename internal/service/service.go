@@ -2,22 +2,35 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 	"os"
 	"sync"
 
+	"api/internal/filter"
 	"api/internal/model"
 	"api/pkg/logger"
 )
 
 // DataService defines the interface for data operations.
 type DataService interface {
-	GetAllData() []model.Data
-	GetDataByGUID(guid string) *model.Data
+	// All returns an iterator over a snapshot of the data taken when it is
+	// called; reloads during iteration do not affect it.
+	All(ctx context.Context) iter.Seq[model.Data]
+	GetDataByGUID(ctx context.Context, guid string) *model.Data
+	// GetDataByGUIDs returns the entries with any of guids, keyed by
+	// GUID, all from the same snapshot of the data.
+	GetDataByGUIDs(ctx context.Context, guids []string) map[string]model.Data
+	// Filter returns an iterator over the entries of a snapshot of the
+	// data that match expr.
+	Filter(ctx context.Context, expr filter.Expr) iter.Seq[model.Data]
 }
 
-// Service handles data loading and caching.
+// Service handles data loading and caching. The data slice is replaced on
+// reload and never modified in place, so readers may keep using a slice
+// obtained under the lock after releasing it.
 type Service struct {
 	data     []model.Data
 	mu       sync.RWMutex
@@ -53,24 +66,43 @@ func (s *Service) LoadData() error {
 	}
 
 	s.data = data
-	logger.Info("Data loaded successfully", "count", len(data), "file", s.filePath)
+	logger.Component(context.Background(), "service").Info("Data loaded successfully", "count", len(data), "file", s.filePath)
 
 	return nil
 }
 
-// GetAllData returns all data entries.
-func (s *Service) GetAllData() []model.Data {
+// All returns an iterator over the data loaded when All is called, without
+// copying it.
+func (s *Service) All(_ context.Context) iter.Seq[model.Data] {
 	s.mu.RLock()
-	defer s.mu.RUnlock()
+	snapshot := s.data
+	s.mu.RUnlock()
+
+	return func(yield func(model.Data) bool) {
+		for _, d := range snapshot {
+			if !yield(d) {
+				return
+			}
+		}
+	}
+}
 
-	// Return a copy to prevent external modification
-	result := make([]model.Data, len(s.data))
-	copy(result, s.data)
-	return result
+// Filter returns an iterator over the entries matching expr in the data
+// loaded when Filter is called.
+func (s *Service) Filter(ctx context.Context, expr filter.Expr) iter.Seq[model.Data] {
+	all := s.All(ctx)
+	return func(yield func(model.Data) bool) {
+		for d := range all {
+			if expr.Match(d.Field) && !yield(d) {
+				return
+			}
+		}
+	}
 }
 
 // GetDataByGUID returns a data entry by GUID, or nil if not found.
-func (s *Service) GetDataByGUID(guid string) *model.Data {
+// Lookups are logged with the request-scoped logger carried by ctx.
+func (s *Service) GetDataByGUID(ctx context.Context, guid string) *model.Data {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -82,9 +114,32 @@ func (s *Service) GetDataByGUID(guid string) *model.Data {
 		}
 	}
 
+	logger.Component(ctx, "service").Debug("Data not found", "guid", guid)
 	return nil
 }
 
+// GetDataByGUIDs returns the entries with any of guids, keyed by GUID,
+// in a single pass over the data.
+func (s *Service) GetDataByGUIDs(ctx context.Context, guids []string) map[string]model.Data {
+	wanted := make(map[string]struct{}, len(guids))
+	for _, guid := range guids {
+		wanted[guid] = struct{}{}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	found := make(map[string]model.Data, len(wanted))
+	for _, d := range s.data {
+		if _, ok := wanted[d.GUID]; ok {
+			found[d.GUID] = d
+		}
+	}
+
+	logger.Component(ctx, "service").Debug("Batch lookup", "requested", len(wanted), "found", len(found))
+	return found
+}
+
 // Reload reloads data from the file.
 func (s *Service) Reload() error {
 	return s.LoadData()
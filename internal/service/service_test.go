@@ -1,11 +1,17 @@
 package service
 
 import (
+	"context"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+
+	"api/internal/filter"
 )
 
-func TestService_GetAllData(t *testing.T) {
+func TestService_All(t *testing.T) {
 	// Create a temporary test data file
 	testData := `[
 		{
@@ -34,12 +40,12 @@ func TestService_GetAllData(t *testing.T) {
 		t.Fatalf("NewService() error = %v", err)
 	}
 
-	data := svc.GetAllData()
+	data := slices.Collect(svc.All(context.Background()))
 	if len(data) != 1 {
-		t.Errorf("GetAllData() returned %d items, want 1", len(data))
+		t.Fatalf("All() returned %d items, want 1", len(data))
 	}
 	if data[0].GUID != "05024756-765e-41a9-89d7-1407436d9a58" {
-		t.Errorf("GetAllData() GUID = %v, want %v", data[0].GUID, "05024756-765e-41a9-89d7-1407436d9a58")
+		t.Errorf("All() GUID = %v, want %v", data[0].GUID, "05024756-765e-41a9-89d7-1407436d9a58")
 	}
 }
 
@@ -72,7 +78,7 @@ func TestService_GetDataByGUID(t *testing.T) {
 	}
 
 	// Test existing GUID
-	data := svc.GetDataByGUID("05024756-765e-41a9-89d7-1407436d9a58")
+	data := svc.GetDataByGUID(context.Background(), "05024756-765e-41a9-89d7-1407436d9a58")
 	if data == nil {
 		t.Fatal("GetDataByGUID() returned nil for existing GUID")
 	}
@@ -81,7 +87,7 @@ func TestService_GetDataByGUID(t *testing.T) {
 	}
 
 	// Test non-existing GUID
-	data = svc.GetDataByGUID("00000000-0000-0000-0000-000000000000")
+	data = svc.GetDataByGUID(context.Background(), "00000000-0000-0000-0000-000000000000")
 	if data != nil {
 		t.Error("GetDataByGUID() returned data for non-existing GUID")
 	}
@@ -93,3 +99,116 @@ func TestNewService_InvalidFile(t *testing.T) {
 		t.Error("NewService() expected error for nonexistent file")
 	}
 }
+
+func TestService_AllSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	write := func(guids ...string) {
+		var entries []string
+		for _, guid := range guids {
+			entries = append(entries, `{"guid":"`+guid+`","school":"School"}`)
+		}
+		if err := os.WriteFile(path, []byte("["+strings.Join(entries, ",")+"]"), 0o600); err != nil {
+			t.Fatalf("Failed to write test data: %v", err)
+		}
+	}
+	write("a", "b")
+
+	svc, err := NewService(path)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	seq := svc.All(context.Background())
+
+	// A reload after All is called does not change what it yields.
+	write("c")
+	if err := svc.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	var got []string
+	for d := range seq {
+		got = append(got, d.GUID)
+	}
+	if strings.Join(got, ",") != "a,b" {
+		t.Errorf("All() yielded %v, want [a b]", got)
+	}
+
+	got = nil
+	for d := range svc.All(context.Background()) {
+		got = append(got, d.GUID)
+	}
+	if strings.Join(got, ",") != "c" {
+		t.Errorf("All() after reload yielded %v, want [c]", got)
+	}
+}
+
+func TestService_GetDataByGUIDs(t *testing.T) {
+	testData := `[
+		{"guid": "05024756-765e-41a9-89d7-1407436d9a58", "school": "First University"},
+		{"guid": "11111111-1111-1111-1111-111111111111", "school": "Second University"},
+		{"guid": "22222222-2222-2222-2222-222222222222", "school": "Third University"}
+	]`
+
+	tmpFile, err := os.CreateTemp("", "test_data_*.json")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(testData); err != nil {
+		t.Fatalf("Failed to write test data: %v", err)
+	}
+	tmpFile.Close()
+
+	svc, err := NewService(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	found := svc.GetDataByGUIDs(context.Background(), []string{
+		"22222222-2222-2222-2222-222222222222",
+		"00000000-0000-0000-0000-000000000000",
+		"05024756-765e-41a9-89d7-1407436d9a58",
+		"22222222-2222-2222-2222-222222222222",
+	})
+	if len(found) != 2 {
+		t.Fatalf("GetDataByGUIDs() returned %d entries, want 2", len(found))
+	}
+	if got := found["22222222-2222-2222-2222-222222222222"].School; got != "Third University" {
+		t.Errorf("GetDataByGUIDs() school = %v, want %v", got, "Third University")
+	}
+	if _, ok := found["00000000-0000-0000-0000-000000000000"]; ok {
+		t.Error("GetDataByGUIDs() returned data for non-existing GUID")
+	}
+}
+
+func TestService_Filter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	data := `[
+		{"guid": "a", "school": "Oregon", "ncaa": "Division I", "conference": "Pac-12 Conference"},
+		{"guid": "b", "school": "Western Oregon", "ncaa": "Division II", "conference": "Pac-12 Conference"},
+		{"guid": "c", "school": "Iowa", "ncaa": "Division I", "conference": "Big Ten Conference"}
+	]`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("Failed to write test data: %v", err)
+	}
+
+	svc, err := NewService(path)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	expr, err := filter.Parse("conference eq 'Pac-12 Conference' and not ncaa eq 'Division II'", []string{"conference", "ncaa"})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	var got []string
+	for d := range svc.Filter(context.Background(), expr) {
+		got = append(got, d.GUID)
+	}
+	if strings.Join(got, ",") != "a" {
+		t.Errorf("Filter() yielded %v, want [a]", got)
+	}
+}
@@ -0,0 +1,98 @@
+// Package stats counts school records grouped by their properties.
+package stats
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+
+	"api/internal/model"
+)
+
+// Dimension is a property records can be grouped by.
+type Dimension struct {
+	// Name selects the dimension in ?group_by=.
+	Name string
+	// Value returns the group of a record.
+	Value func(d model.Data) string
+}
+
+// dimensions are the supported dimensions, in the order they are reported.
+var dimensions = []Dimension{
+	{Name: "conference", Value: func(d model.Data) string { return d.Conference }},
+	{Name: "ncaa", Value: func(d model.Data) string { return d.NCAA }},
+	{Name: "state", Value: model.Data.State},
+	{Name: "country", Value: model.Data.Country},
+	{Name: "nickname", Value: func(d model.Data) string { return d.Nickname }},
+	{Name: "mascot", Value: func(d model.Data) string { return d.Mascot }},
+}
+
+// Dimensions returns every supported dimension.
+func Dimensions() []Dimension {
+	return slices.Clone(dimensions)
+}
+
+// DimensionNames returns the names of every supported dimension.
+func DimensionNames() []string {
+	names := make([]string, len(dimensions))
+	for i, dim := range dimensions {
+		names[i] = dim.Name
+	}
+	return names
+}
+
+// ByName returns the dimension selected by a ?group_by= value.
+func ByName(name string) (Dimension, bool) {
+	i := slices.IndexFunc(dimensions, func(dim Dimension) bool { return dim.Name == name })
+	if i < 0 {
+		return Dimension{}, false
+	}
+	return dimensions[i], true
+}
+
+// Count is the number of records in one group.
+type Count struct {
+	Value string `json:"value" xml:"value,attr" yaml:"value" doc:"Value of the property; empty for records without one"`
+	Count int    `json:"count" xml:"count,attr" yaml:"count" doc:"Number of records with the value"`
+}
+
+// Facet is the record counts for each value of one dimension, largest
+// first and alphabetically on ties.
+type Facet struct {
+	Name   string  `json:"name" xml:"name,attr" yaml:"name" doc:"Name of the dimension"`
+	Counts []Count `json:"counts" xml:"count" yaml:"counts" doc:"Record counts, largest first"`
+}
+
+// Stats is the number of records and their facets.
+type Stats struct {
+	Total  int     `json:"total" xml:"total,attr" yaml:"total" doc:"Number of records"`
+	Facets []Facet `json:"facets" xml:"facet" yaml:"facets" doc:"Record counts for each dimension"`
+}
+
+// Compute counts records by each of dims.
+func Compute(records iter.Seq[model.Data], dims []Dimension) Stats {
+	groups := make([]map[string]int, len(dims))
+	for i := range dims {
+		groups[i] = map[string]int{}
+	}
+	total := 0
+	for d := range records {
+		total++
+		for i, dim := range dims {
+			groups[i][dim.Value(d)]++
+		}
+	}
+
+	s := Stats{Total: total, Facets: make([]Facet, len(dims))}
+	for i, dim := range dims {
+		counts := make([]Count, 0, len(groups[i]))
+		for value, n := range groups[i] {
+			counts = append(counts, Count{Value: value, Count: n})
+		}
+		slices.SortFunc(counts, func(a, b Count) int {
+			return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Value, b.Value))
+		})
+		s.Facets[i] = Facet{Name: dim.Name, Counts: counts}
+	}
+	return s
+}
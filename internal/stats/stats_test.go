@@ -0,0 +1,48 @@
+package stats
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"api/internal/model"
+)
+
+var records = []model.Data{
+	{School: "A", Location: "Ames, IA, USA", NCAA: "Division I", Conference: "Big 12 Conference"},
+	{School: "B", Location: "Iowa City, IA, USA", NCAA: "Division I", Conference: "Big Ten Conference"},
+	{School: "C", Location: "Lincoln, NE, USA", NCAA: "Division I", Conference: "Big Ten Conference"},
+	{School: "D", Location: "Toronto, Canada"},
+}
+
+func TestCompute(t *testing.T) {
+	dims := Dimensions()[:4]
+	s := Compute(slices.Values(records), dims)
+
+	assert.Equal(t, Stats{
+		Total: 4,
+		Facets: []Facet{
+			{Name: "conference", Counts: []Count{{"Big Ten Conference", 2}, {"", 1}, {"Big 12 Conference", 1}}},
+			{Name: "ncaa", Counts: []Count{{"Division I", 3}, {"", 1}}},
+			{Name: "state", Counts: []Count{{"IA", 2}, {"", 1}, {"NE", 1}}},
+			{Name: "country", Counts: []Count{{"USA", 3}, {"Canada", 1}}},
+		},
+	}, s)
+}
+
+func TestCompute_Empty(t *testing.T) {
+	s := Compute(slices.Values([]model.Data(nil)), Dimensions()[:1])
+	assert.Equal(t, Stats{Facets: []Facet{{Name: "conference", Counts: []Count{}}}}, s)
+}
+
+func TestByName(t *testing.T) {
+	for _, name := range DimensionNames() {
+		dim, ok := ByName(name)
+		require.True(t, ok, name)
+		assert.Equal(t, name, dim.Name)
+	}
+	_, ok := ByName("coach")
+	assert.False(t, ok)
+}
@@ -0,0 +1,59 @@
+// Package version reports build and runtime information about the running binary.
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// startTime records when the process started.
+var startTime = time.Now()
+
+// Info holds build and runtime information.
+type Info struct {
+	Version   string    `json:"version"`
+	Revision  string    `json:"revision,omitempty"`
+	BuildTime string    `json:"build_time,omitempty"`
+	Dirty     bool      `json:"dirty"`
+	GoVersion string    `json:"go_version"`
+	StartTime time.Time `json:"start_time"`
+	Uptime    string    `json:"uptime"`
+}
+
+// Get returns the build information embedded in the binary together with
+// the process start time and current uptime.
+func Get() Info {
+	info := Info{
+		Version:   "unknown",
+		GoVersion: runtime.Version(),
+		StartTime: startTime,
+		Uptime:    time.Since(startTime).Round(time.Second).String(),
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	if bi.Main.Version != "" {
+		info.Version = bi.Main.Version
+	}
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Revision = setting.Value
+		case "vcs.time":
+			info.BuildTime = setting.Value
+		case "vcs.modified":
+			info.Dirty = setting.Value == "true"
+		}
+	}
+
+	return info
+}
+
+// StartTime returns the time at which the process started.
+func StartTime() time.Time {
+	return startTime
+}
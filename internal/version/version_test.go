@@ -0,0 +1,24 @@
+package version
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestGet(t *testing.T) {
+	info := Get()
+
+	if info.Version == "" {
+		t.Error("Get() Version should not be empty")
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("Get() GoVersion = %v, want %v", info.GoVersion, runtime.Version())
+	}
+	if !info.StartTime.Equal(StartTime()) {
+		t.Errorf("Get() StartTime = %v, want %v", info.StartTime, StartTime())
+	}
+	if _, err := time.ParseDuration(info.Uptime); err != nil {
+		t.Errorf("Get() Uptime = %q is not a duration: %v", info.Uptime, err)
+	}
+}
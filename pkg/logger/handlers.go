@@ -0,0 +1,217 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fanoutHandler delivers every record to each of its handlers.
+type fanoutHandler struct {
+	handlers []slog.Handler
+}
+
+// Enabled implements slog.Handler.
+func (h *fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle implements slog.Handler. A failing output does not prevent
+// delivery to the others; all errors are returned joined.
+func (h *fanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, handler := range h.handlers {
+		if !handler.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := handler.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return &fanoutHandler{handlers: handlers}
+}
+
+// WithGroup implements slog.Handler.
+func (h *fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return &fanoutHandler{handlers: handlers}
+}
+
+// SampleRate describes how records are sampled within one tick: the first
+// Initial records with the same level and message are logged, then every
+// Thereafter-th one. A Thereafter of zero drops everything after Initial.
+type SampleRate struct {
+	Initial    int
+	Thereafter int
+}
+
+// SamplingOptions configures per-level log sampling.
+type SamplingOptions struct {
+	// Tick is the window after which counters reset. Defaults to one second.
+	Tick time.Duration
+	// Levels maps a level to its sample rate. Levels not present are
+	// never sampled.
+	Levels map[slog.Level]SampleRate
+}
+
+// ParseSampling parses a sampling specification of the form
+// "level=initial:thereafter[,level=initial:thereafter...]",
+// e.g. "info=100:10,debug=10:100".
+func ParseSampling(spec string) (map[slog.Level]SampleRate, error) {
+	levels := make(map[slog.Level]SampleRate)
+	if strings.TrimSpace(spec) == "" {
+		return levels, nil
+	}
+
+	for _, part := range strings.Split(spec, ",") {
+		name, rate, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid sampling rule %q: expected level=initial:thereafter", part)
+		}
+
+		level, ok := levelFromName(name)
+		if !ok {
+			return nil, fmt.Errorf("invalid sampling level %q", name)
+		}
+
+		initialStr, thereafterStr, ok := strings.Cut(rate, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid sampling rate %q: expected initial:thereafter", rate)
+		}
+		initial, err := strconv.Atoi(initialStr)
+		if err != nil || initial < 0 {
+			return nil, fmt.Errorf("invalid sampling initial count %q", initialStr)
+		}
+		thereafter, err := strconv.Atoi(thereafterStr)
+		if err != nil || thereafter < 0 {
+			return nil, fmt.Errorf("invalid sampling thereafter count %q", thereafterStr)
+		}
+
+		levels[level] = SampleRate{Initial: initial, Thereafter: thereafter}
+	}
+
+	return levels, nil
+}
+
+// samplingHandler drops records exceeding the configured per-level rates.
+// Records are counted per level and message within each tick.
+type samplingHandler struct {
+	next  slog.Handler
+	opts  SamplingOptions
+	state *samplingState
+}
+
+// samplingState holds counters shared by a sampling handler and its derivatives.
+type samplingState struct {
+	mu       sync.Mutex
+	counters map[samplingKey]*samplingCounter
+	now      func() time.Time
+}
+
+// samplingKey identifies a stream of similar records.
+type samplingKey struct {
+	level slog.Level
+	msg   string
+}
+
+// samplingCounter counts records within the current tick.
+type samplingCounter struct {
+	resetAt time.Time
+	count   int
+}
+
+// newSamplingHandler wraps next with sampling according to opts.
+func newSamplingHandler(next slog.Handler, opts SamplingOptions) *samplingHandler {
+	if opts.Tick <= 0 {
+		opts.Tick = time.Second
+	}
+	return &samplingHandler{
+		next: next,
+		opts: opts,
+		state: &samplingState{
+			counters: make(map[samplingKey]*samplingCounter),
+			now:      time.Now,
+		},
+	}
+}
+
+// Enabled implements slog.Handler.
+func (h *samplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	rate, ok := h.opts.Levels[r.Level]
+	if ok && !h.state.allow(samplingKey{level: r.Level, msg: r.Message}, rate, h.opts.Tick) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{next: h.next.WithAttrs(attrs), opts: h.opts, state: h.state}
+}
+
+// WithGroup implements slog.Handler.
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{next: h.next.WithGroup(name), opts: h.opts, state: h.state}
+}
+
+// allow records one occurrence of key and reports whether it should be logged.
+func (s *samplingState) allow(key samplingKey, rate SampleRate, tick time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	counter, ok := s.counters[key]
+	if !ok || !now.Before(counter.resetAt) {
+		counter = &samplingCounter{resetAt: now.Add(tick)}
+		s.counters[key] = counter
+	}
+	counter.count++
+
+	if counter.count <= rate.Initial {
+		return true
+	}
+	return rate.Thereafter > 0 && (counter.count-rate.Initial)%rate.Thereafter == 0
+}
+
+// levelFromName returns the slog level for a level name.
+func levelFromName(name string) (slog.Level, bool) {
+	switch name {
+	case "debug":
+		return slog.LevelDebug, true
+	case "info":
+		return slog.LevelInfo, true
+	case "warn":
+		return slog.LevelWarn, true
+	case "error":
+		return slog.LevelError, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFanoutHandler(t *testing.T) {
+	var debugBuf, warnBuf bytes.Buffer
+	h := &fanoutHandler{handlers: []slog.Handler{
+		slog.NewTextHandler(&debugBuf, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		slog.NewJSONHandler(&warnBuf, &slog.HandlerOptions{Level: slog.LevelWarn}),
+	}}
+	l := slog.New(h).With("service", "api")
+
+	l.Info("info message")
+	l.Warn("warn message")
+
+	if !strings.Contains(debugBuf.String(), "info message") || !strings.Contains(debugBuf.String(), "warn message") {
+		t.Errorf("debug output = %q, want both messages", debugBuf.String())
+	}
+	if strings.Contains(warnBuf.String(), "info message") {
+		t.Errorf("warn output = %q, should not contain info message", warnBuf.String())
+	}
+	if !strings.Contains(warnBuf.String(), `"service":"api"`) {
+		t.Errorf("warn output = %q, want attributes propagated", warnBuf.String())
+	}
+}
+
+func TestSamplingHandler(t *testing.T) {
+	var buf bytes.Buffer
+	h := newSamplingHandler(slog.NewTextHandler(&buf, nil), SamplingOptions{
+		Tick: time.Second,
+		Levels: map[slog.Level]SampleRate{
+			slog.LevelInfo: {Initial: 2, Thereafter: 3},
+		},
+	})
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	h.state.now = func() time.Time { return clock }
+	l := slog.New(h)
+
+	for i := 0; i < 8; i++ {
+		l.Info("HTTP Request")
+	}
+	l.Warn("not sampled")
+
+	// Records 1, 2 (initial) and 5, 8 (every third thereafter) are kept.
+	if got := strings.Count(buf.String(), "HTTP Request"); got != 4 {
+		t.Errorf("sampled count = %d, want 4", got)
+	}
+	if !strings.Contains(buf.String(), "not sampled") {
+		t.Error("levels without a rate should never be sampled")
+	}
+
+	// Counters reset after the tick.
+	buf.Reset()
+	clock = clock.Add(time.Second)
+	l.Info("HTTP Request")
+	if !strings.Contains(buf.String(), "HTTP Request") {
+		t.Error("first record after the tick should be logged")
+	}
+}
+
+func TestParseSampling(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    map[slog.Level]SampleRate
+		wantErr bool
+	}{
+		{name: "empty", spec: "", want: map[slog.Level]SampleRate{}},
+		{
+			name: "multiple levels",
+			spec: "info=100:10, debug=10:0",
+			want: map[slog.Level]SampleRate{
+				slog.LevelInfo:  {Initial: 100, Thereafter: 10},
+				slog.LevelDebug: {Initial: 10, Thereafter: 0},
+			},
+		},
+		{name: "missing rate", spec: "info", wantErr: true},
+		{name: "unknown level", spec: "trace=1:1", wantErr: true},
+		{name: "missing thereafter", spec: "info=10", wantErr: true},
+		{name: "negative", spec: "info=-1:1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSampling(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSampling(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseSampling(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+			for level, rate := range tt.want {
+				if got[level] != rate {
+					t.Errorf("ParseSampling(%q)[%v] = %v, want %v", tt.spec, level, got[level], rate)
+				}
+			}
+		})
+	}
+}
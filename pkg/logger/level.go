@@ -0,0 +1,241 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ComponentKey is the attribute key naming the package or subsystem a
+// logger belongs to. Loggers carrying it honour per-component level overrides.
+const ComponentKey = "component"
+
+var (
+	// level is the global minimum level, adjustable at runtime.
+	level = new(slog.LevelVar)
+
+	// overridesMu serializes writers of overrides.
+	overridesMu sync.Mutex
+	// overrides maps a component name to its level. The map is replaced,
+	// never mutated, so readers need no lock.
+	overrides atomic.Pointer[map[string]slog.Level]
+)
+
+// levelOrder lists the supported levels from most to least verbose.
+var levelOrder = []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+
+// Component returns the logger carried by ctx tagged with the given
+// component name, so that per-component level overrides apply to it.
+func Component(ctx context.Context, name string) *slog.Logger {
+	return FromContext(ctx).With(ComponentKey, name)
+}
+
+// SetLevel changes the global log level at runtime.
+func SetLevel(name string) error {
+	l, ok := levelFromName(name)
+	if !ok {
+		return fmt.Errorf("invalid log level: %s (must be debug, info, warn, or error)", name)
+	}
+	level.Set(l)
+	return nil
+}
+
+// ValidLevel reports whether name is a supported level name.
+func ValidLevel(name string) bool {
+	_, ok := levelFromName(name)
+	return ok
+}
+
+// LevelNames returns the supported level names from most to least verbose.
+func LevelNames() []string {
+	names := make([]string, len(levelOrder))
+	for i, l := range levelOrder {
+		names[i] = levelName(l)
+	}
+	return names
+}
+
+// GetLevel returns the name of the current global log level.
+func GetLevel() string {
+	return levelName(level.Level())
+}
+
+// CycleLevel moves the global level one step and returns the new level
+// name. When verbose is true it steps towards debug, wrapping from debug
+// to error; otherwise it steps towards error, wrapping from error to debug.
+func CycleLevel(verbose bool) string {
+	current := 0
+	for i, l := range levelOrder {
+		if l == level.Level() {
+			current = i
+			break
+		}
+	}
+
+	step := 1
+	if verbose {
+		step = -1
+	}
+	next := (current + step + len(levelOrder)) % len(levelOrder)
+	level.Set(levelOrder[next])
+
+	return levelName(levelOrder[next])
+}
+
+// SetComponentLevel sets a level override for a component. An empty
+// level name removes the override.
+func SetComponentLevel(component, name string) error {
+	if component == "" {
+		return fmt.Errorf("component name cannot be empty")
+	}
+
+	var (
+		l  slog.Level
+		ok bool
+	)
+	if name != "" {
+		if l, ok = levelFromName(name); !ok {
+			return fmt.Errorf("invalid log level for %s: %s (must be debug, info, warn, or error)", component, name)
+		}
+	}
+
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+
+	next := make(map[string]slog.Level)
+	if current := overrides.Load(); current != nil {
+		for k, v := range *current {
+			next[k] = v
+		}
+	}
+	if name == "" {
+		delete(next, component)
+	} else {
+		next[component] = l
+	}
+	overrides.Store(&next)
+
+	return nil
+}
+
+// SetComponentLevels replaces all component level overrides.
+func SetComponentLevels(levels map[string]slog.Level) {
+	next := make(map[string]slog.Level, len(levels))
+	for k, v := range levels {
+		next[k] = v
+	}
+
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	overrides.Store(&next)
+}
+
+// ComponentLevels returns the current component level overrides by name.
+func ComponentLevels() map[string]string {
+	result := make(map[string]string)
+	if current := overrides.Load(); current != nil {
+		for k, v := range *current {
+			result[k] = levelName(v)
+		}
+	}
+	return result
+}
+
+// ParseComponentLevels parses overrides of the form
+// "component=level[,component=level...]", e.g. "service=debug,middleware=warn".
+func ParseComponentLevels(spec string) (map[string]slog.Level, error) {
+	levels := make(map[string]slog.Level)
+	if strings.TrimSpace(spec) == "" {
+		return levels, nil
+	}
+
+	for _, part := range strings.Split(spec, ",") {
+		component, name, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || component == "" {
+			return nil, fmt.Errorf("invalid level override %q: expected component=level", part)
+		}
+		l, ok := levelFromName(name)
+		if !ok {
+			return nil, fmt.Errorf("invalid level for %s: %s", component, name)
+		}
+		levels[component] = l
+	}
+
+	return levels, nil
+}
+
+// FormatComponentLevels renders overrides in the form accepted by
+// ParseComponentLevels, sorted by component.
+func FormatComponentLevels(levels map[string]slog.Level) string {
+	parts := make([]string, 0, len(levels))
+	for component, l := range levels {
+		parts = append(parts, component+"="+levelName(l))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// effectiveLevel returns the minimum level for component.
+func effectiveLevel(component string) slog.Level {
+	if component != "" {
+		if current := overrides.Load(); current != nil {
+			if l, ok := (*current)[component]; ok {
+				return l
+			}
+		}
+	}
+	return level.Level()
+}
+
+// levelName returns the lowercase name of a level.
+func levelName(l slog.Level) string {
+	switch l {
+	case slog.LevelDebug:
+		return "debug"
+	case slog.LevelInfo:
+		return "info"
+	case slog.LevelWarn:
+		return "warn"
+	case slog.LevelError:
+		return "error"
+	default:
+		return strings.ToLower(l.String())
+	}
+}
+
+// levelHandler gates records on the runtime level, honouring the
+// override for the component named by a ComponentKey attribute.
+type levelHandler struct {
+	next      slog.Handler
+	component string
+}
+
+// Enabled implements slog.Handler.
+func (h *levelHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return l >= effectiveLevel(h.component) && h.next.Enabled(ctx, l)
+}
+
+// Handle implements slog.Handler.
+func (h *levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	component := h.component
+	for _, attr := range attrs {
+		if attr.Key == ComponentKey {
+			component = attr.Value.String()
+		}
+	}
+	return &levelHandler{next: h.next.WithAttrs(attrs), component: component}
+}
+
+// WithGroup implements slog.Handler.
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{next: h.next.WithGroup(name), component: h.component}
+}
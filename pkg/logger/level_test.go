@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// captureLogger installs a global logger writing text to a buffer and
+// restores the default levels when the test ends.
+func captureLogger(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	SetDefault(slog.New(&levelHandler{next: slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})}))
+	t.Cleanup(func() {
+		level.Set(slog.LevelInfo)
+		SetComponentLevels(nil)
+		SetDefault(nil)
+	})
+	return &buf
+}
+
+func TestSetLevel(t *testing.T) {
+	buf := captureLogger(t)
+
+	if err := SetLevel("warn"); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	Info("hidden")
+	Warn("shown")
+
+	if strings.Contains(buf.String(), "hidden") || !strings.Contains(buf.String(), "shown") {
+		t.Errorf("output = %q, want only the warn record", buf.String())
+	}
+	if GetLevel() != "warn" {
+		t.Errorf("GetLevel() = %v, want warn", GetLevel())
+	}
+	if err := SetLevel("loud"); err == nil {
+		t.Error("SetLevel() expected error for unknown level")
+	}
+	for _, name := range LevelNames() {
+		if !ValidLevel(name) {
+			t.Errorf("LevelNames() contains invalid level %q", name)
+		}
+	}
+}
+
+func TestComponentLevels(t *testing.T) {
+	buf := captureLogger(t)
+
+	if err := SetComponentLevel("service", "debug"); err != nil {
+		t.Fatalf("SetComponentLevel() error = %v", err)
+	}
+	if err := SetComponentLevel("middleware", "error"); err != nil {
+		t.Fatalf("SetComponentLevel() error = %v", err)
+	}
+
+	ctx := context.Background()
+	Component(ctx, "service").Debug("service debug")
+	Component(ctx, "middleware").Warn("middleware warn")
+	Component(ctx, "handler").Debug("handler debug")
+	Component(ctx, "handler").Info("handler info")
+
+	out := buf.String()
+	if !strings.Contains(out, "service debug") {
+		t.Error("service override to debug should allow debug records")
+	}
+	if strings.Contains(out, "middleware warn") {
+		t.Error("middleware override to error should drop warn records")
+	}
+	if strings.Contains(out, "handler debug") || !strings.Contains(out, "handler info") {
+		t.Error("components without overrides should follow the global level")
+	}
+
+	if got := ComponentLevels(); got["service"] != "debug" || got["middleware"] != "error" {
+		t.Errorf("ComponentLevels() = %v", got)
+	}
+
+	// Clearing an override falls back to the global level
+	if err := SetComponentLevel("service", ""); err != nil {
+		t.Fatalf("SetComponentLevel() error = %v", err)
+	}
+	if _, ok := ComponentLevels()["service"]; ok {
+		t.Error("override should be removed")
+	}
+	if err := SetComponentLevel("service", "chatty"); err == nil {
+		t.Error("SetComponentLevel() expected error for unknown level")
+	}
+}
+
+func TestCycleLevel(t *testing.T) {
+	captureLogger(t)
+
+	want := []string{"debug", "error", "warn"}
+	for _, w := range want {
+		if got := CycleLevel(true); got != w {
+			t.Fatalf("CycleLevel(true) = %v, want %v", got, w)
+		}
+	}
+
+	want = []string{"error", "debug", "info"}
+	for _, w := range want {
+		if got := CycleLevel(false); got != w {
+			t.Fatalf("CycleLevel(false) = %v, want %v", got, w)
+		}
+	}
+}
+
+func TestParseComponentLevels(t *testing.T) {
+	levels, err := ParseComponentLevels("service=debug, middleware=warn")
+	if err != nil {
+		t.Fatalf("ParseComponentLevels() error = %v", err)
+	}
+	if levels["service"] != slog.LevelDebug || levels["middleware"] != slog.LevelWarn {
+		t.Errorf("ParseComponentLevels() = %v", levels)
+	}
+	if got := FormatComponentLevels(levels); got != "middleware=warn,service=debug" {
+		t.Errorf("FormatComponentLevels() = %v", got)
+	}
+
+	for _, spec := range []string{"service", "=debug", "service=loud"} {
+		if _, err := ParseComponentLevels(spec); err == nil {
+			t.Errorf("ParseComponentLevels(%q) expected error", spec)
+		}
+	}
+}
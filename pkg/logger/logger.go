@@ -2,70 +2,295 @@
 package logger
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
-	"os"
+	"maps"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// SinkCloseDelay is how long outputs replaced by Setup or Reconfigure stay
+// open, so loggers derived from the previous global logger, such as those
+// of in-flight requests, can finish writing to them.
+var SinkCloseDelay = time.Minute
+
 var (
-	// Logger is the global structured logger instance.
-	Logger *slog.Logger
+	// global is the global structured logger.
+	global atomic.Pointer[slog.Logger]
+
+	// stateMu guards sinks, retired and applied.
+	stateMu sync.Mutex
+	// sinks holds the outputs of the current global logger.
+	sinks []io.Closer
+	// retired holds replaced outputs waiting to be closed.
+	retired = map[*retirement]struct{}{}
+	// applied is the configuration of the current global logger.
+	applied Options
 )
 
+// retirement is a set of replaced outputs closed when its timer fires.
+type retirement struct {
+	closers []io.Closer
+	timer   *time.Timer
+}
+
+// Default returns the global logger, or nil if it is not initialized.
+func Default() *slog.Logger {
+	return global.Load()
+}
+
+// SetDefault replaces the global logger. Outputs opened by Setup are left
+// open; use Close to close them.
+func SetDefault(l *slog.Logger) {
+	global.Store(l)
+}
+
+// Options configures the global logger.
+type Options struct {
+	// Level is the minimum level to log (debug, info, warn, error). It can
+	// be changed later with SetLevel.
+	Level string
+	// ComponentLevels overrides Level for loggers tagged with ComponentKey.
+	ComponentLevels map[string]slog.Level
+	// JSON selects JSON output instead of text.
+	JSON bool
+	// Outputs lists sink specifications; see OpenSink. Every record is
+	// written to all outputs. Defaults to stdout when empty.
+	Outputs []string
+	// File configures rotation for file outputs.
+	File FileOptions
+	// Sampling configures per-level sampling. Levels without a rate are
+	// never sampled.
+	Sampling SamplingOptions
+	// Redact configures masking of sensitive values in every record.
+	Redact RedactOptions
+}
+
+// contextKey is the key under which a request-scoped logger is stored in a context.
+type contextKey struct{}
+
+// WithContext returns a copy of ctx that carries l.
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx. If ctx has no logger the
+// global logger is returned, and if that is not initialized a logger that
+// discards all output is returned, so the result is always safe to use.
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(*slog.Logger); ok && l != nil {
+			return l
+		}
+	}
+	if l := global.Load(); l != nil {
+		return l
+	}
+	return slog.New(slog.DiscardHandler)
+}
+
 // Init initializes the global logger with the specified log level.
 // If jsonOutput is true, logs will be in JSON format.
 func Init(level string, jsonOutput bool) {
-	var logLevel slog.Level
-	switch level {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "info":
-		logLevel = slog.LevelInfo
-	case "warn":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
-	default:
-		logLevel = slog.LevelInfo
+	// Stdout cannot fail to open, so the error is always nil.
+	_ = Setup(Options{Level: level, JSON: jsonOutput})
+}
+
+// Setup initializes the global logger from opts, opening every configured
+// output and applying the levels. Outputs opened by a previous call are
+// closed after SinkCloseDelay. On error the previous logger is left in
+// place.
+func Setup(opts Options) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	if err := installHandler(opts); err != nil {
+		return err
+	}
+	level.Set(parseLevel(opts.Level))
+	SetComponentLevels(opts.ComponentLevels)
+	applied = opts
+	return nil
+}
+
+// Reconfigure applies opts to the global logger set up by Setup, changing
+// only what differs from the options last applied: outputs are reopened
+// only if an output setting changed, and the global and component levels
+// are reset only if their configured values changed, so levels changed at
+// runtime survive unrelated reloads. On error nothing is changed.
+func Reconfigure(opts Options) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	if !reflect.DeepEqual(outputOptions(opts), outputOptions(applied)) {
+		if err := installHandler(opts); err != nil {
+			return err
+		}
+	}
+	if opts.Level != applied.Level {
+		level.Set(parseLevel(opts.Level))
+	}
+	if !maps.Equal(opts.ComponentLevels, applied.ComponentLevels) {
+		SetComponentLevels(opts.ComponentLevels)
+	}
+	applied = opts
+	return nil
+}
+
+// outputOptions returns opts without the level settings, which can change
+// without reopening outputs.
+func outputOptions(opts Options) Options {
+	opts.Level = ""
+	opts.ComponentLevels = nil
+	return opts
+}
+
+// installHandler opens the outputs of opts and installs a global logger
+// writing to them, retiring the previous outputs. stateMu must be held.
+func installHandler(opts Options) error {
+	outputs := opts.Outputs
+	if len(outputs) == 0 {
+		outputs = []string{"stdout"}
 	}
 
-	opts := &slog.HandlerOptions{
-		Level: logLevel,
+	// Outputs accept everything; levelHandler applies the runtime level.
+	handlerOpts := &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	}
+
+	var (
+		handlers []slog.Handler
+		opened   []io.Closer
+	)
+	for _, spec := range outputs {
+		w, err := OpenSink(spec, opts.File)
+		if err != nil {
+			closeAll(opened)
+			return fmt.Errorf("could not open log output %q: %w", spec, err)
+		}
+		opened = append(opened, w)
+
+		if sw, ok := w.(*syslogWriter); ok {
+			handlers = append(handlers, newSyslogHandler(sw, opts.JSON, handlerOpts))
+		} else {
+			handlers = append(handlers, newFormatHandler(w, opts.JSON, handlerOpts))
+		}
 	}
 
 	var handler slog.Handler
-	if jsonOutput {
-		handler = slog.NewJSONHandler(os.Stdout, opts)
+	if len(handlers) == 1 {
+		handler = handlers[0]
+	} else {
+		handler = &fanoutHandler{handlers: handlers}
+	}
+	if len(opts.Sampling.Levels) > 0 {
+		handler = newSamplingHandler(handler, opts.Sampling)
+	}
+	if opts.Redact.enabled() {
+		rh := newRedactHandler(handler, opts.Redact)
+		activeRedactor.Store(rh.r)
+		handler = rh
 	} else {
-		handler = slog.NewTextHandler(os.Stdout, opts)
+		activeRedactor.Store(nil)
 	}
 
-	Logger = slog.New(handler)
+	global.Store(slog.New(&levelHandler{next: handler}))
+	retire(sinks)
+	sinks = opened
+	return nil
+}
+
+// retire closes closers after SinkCloseDelay, or on Close if that comes
+// first. stateMu must be held.
+func retire(closers []io.Closer) {
+	if len(closers) == 0 {
+		return
+	}
+	r := &retirement{closers: closers}
+	retired[r] = struct{}{}
+	r.timer = time.AfterFunc(SinkCloseDelay, func() {
+		stateMu.Lock()
+		_, pending := retired[r]
+		delete(retired, r)
+		stateMu.Unlock()
+		if pending {
+			closeAll(r.closers)
+		}
+	})
+}
+
+// Close closes the outputs of the global logger and any replaced outputs
+// not closed yet.
+func Close() error {
+	stateMu.Lock()
+	closers := sinks
+	sinks = nil
+	for r := range retired {
+		r.timer.Stop()
+		closers = append(closers, r.closers...)
+	}
+	clear(retired)
+	applied = Options{}
+	stateMu.Unlock()
+	return closeAll(closers)
+}
+
+// closeAll closes every closer, returning the joined errors.
+func closeAll(closers []io.Closer) error {
+	var errs []error
+	for _, c := range closers {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// newFormatHandler creates a text or JSON handler writing to w.
+func newFormatHandler(w io.Writer, jsonOutput bool, opts *slog.HandlerOptions) slog.Handler {
+	if jsonOutput {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+// parseLevel converts a level name into a slog.Level, defaulting to info.
+func parseLevel(level string) slog.Level {
+	if l, ok := levelFromName(level); ok {
+		return l
+	}
+	return slog.LevelInfo
 }
 
 // Debug logs a debug message.
 func Debug(msg string, args ...any) {
-	if Logger != nil {
-		Logger.Debug(msg, args...)
+	if l := global.Load(); l != nil {
+		l.Debug(msg, args...)
 	}
 }
 
 // Info logs an info message.
 func Info(msg string, args ...any) {
-	if Logger != nil {
-		Logger.Info(msg, args...)
+	if l := global.Load(); l != nil {
+		l.Info(msg, args...)
 	}
 }
 
 // Warn logs a warning message.
 func Warn(msg string, args ...any) {
-	if Logger != nil {
-		Logger.Warn(msg, args...)
+	if l := global.Load(); l != nil {
+		l.Warn(msg, args...)
 	}
 }
 
 // Error logs an error message.
 func Error(msg string, args ...any) {
-	if Logger != nil {
-		Logger.Error(msg, args...)
+	if l := global.Load(); l != nil {
+		l.Error(msg, args...)
 	}
 }
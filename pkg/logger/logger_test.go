@@ -1,26 +1,34 @@
 package logger
 
 import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestInit(t *testing.T) {
 	// Test text output
 	Init("info", false)
-	if Logger == nil {
-		t.Error("Logger should not be nil after Init")
+	if Default() == nil {
+		t.Error("Default() should not be nil after Init")
 	}
 
 	// Test JSON output
 	Init("debug", true)
-	if Logger == nil {
-		t.Error("Logger should not be nil after Init")
+	if Default() == nil {
+		t.Error("Default() should not be nil after Init")
 	}
 
 	// Test invalid log level (should default to info)
 	Init("invalid", false)
-	if Logger == nil {
-		t.Error("Logger should not be nil after Init")
+	if Default() == nil {
+		t.Error("Default() should not be nil after Init")
 	}
 }
 
@@ -34,9 +42,255 @@ func TestLogFunctions(_ *testing.T) {
 	Error("test error message", "key", "value")
 
 	// Test with nil logger (should not panic)
-	Logger = nil
+	SetDefault(nil)
 	Debug("test", "key", "value")
 	Info("test", "key", "value")
 	Warn("test", "key", "value")
 	Error("test", "key", "value")
 }
+
+func TestContextLogger(t *testing.T) {
+	// Without a logger in context or globally, FromContext must not return nil
+	SetDefault(nil)
+	if FromContext(context.Background()) == nil {
+		t.Fatal("FromContext() should never return nil")
+	}
+
+	// Falls back to the global logger
+	Init("info", false)
+	if FromContext(context.Background()) != Default() {
+		t.Error("FromContext() should return the global logger when ctx has none")
+	}
+
+	// Returns the logger attached with WithContext
+	var buf bytes.Buffer
+	child := slog.New(slog.NewTextHandler(&buf, nil)).With("request_id", "abc-123")
+	ctx := WithContext(context.Background(), child)
+	FromContext(ctx).Info("hello")
+
+	if !strings.Contains(buf.String(), "request_id=abc-123") {
+		t.Errorf("FromContext() logger output = %q, want request_id attribute", buf.String())
+	}
+}
+
+func TestSetup_MultipleOutputs(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.log")
+	second := filepath.Join(dir, "second.log")
+
+	err := Setup(Options{
+		Level:   "info",
+		JSON:    true,
+		Outputs: []string{"file:" + first, "file:" + second},
+	})
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	Info("fan out", "key", "value")
+	if err := Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	for _, path := range []string{first, second} {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) error = %v", path, err)
+		}
+		if !strings.Contains(string(content), `"msg":"fan out"`) {
+			t.Errorf("%s = %q, want the logged record", path, content)
+		}
+	}
+
+	// An invalid output fails without replacing the current logger
+	current := Default()
+	if err := Setup(Options{Outputs: []string{"bogus"}}); err == nil {
+		t.Error("Setup() expected error for unknown output")
+	}
+	if Default() != current {
+		t.Error("Setup() should keep the previous logger on error")
+	}
+}
+
+func TestReconfigure_KeepsRuntimeLevels(t *testing.T) {
+	t.Cleanup(func() {
+		_ = Close()
+		level.Set(slog.LevelInfo)
+		SetComponentLevels(nil)
+	})
+
+	opts := Options{Level: "info", ComponentLevels: map[string]slog.Level{"service": slog.LevelDebug}}
+	if err := Setup(opts); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	if err := SetLevel("debug"); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	if err := SetComponentLevel("handler", "warn"); err != nil {
+		t.Fatalf("SetComponentLevel() error = %v", err)
+	}
+
+	// An unrelated change keeps the levels set at runtime.
+	opts.JSON = true
+	if err := Reconfigure(opts); err != nil {
+		t.Fatalf("Reconfigure() error = %v", err)
+	}
+	if got := GetLevel(); got != "debug" {
+		t.Errorf("GetLevel() = %q after unrelated reload, want debug", got)
+	}
+	if got := ComponentLevels()["handler"]; got != "warn" {
+		t.Errorf("handler level = %q after unrelated reload, want warn", got)
+	}
+
+	// A changed level is applied.
+	opts.Level = "error"
+	if err := Reconfigure(opts); err != nil {
+		t.Fatalf("Reconfigure() error = %v", err)
+	}
+	if got := GetLevel(); got != "error" {
+		t.Errorf("GetLevel() = %q, want error", got)
+	}
+	if got := ComponentLevels()["handler"]; got != "warn" {
+		t.Errorf("handler level = %q after level change, want warn", got)
+	}
+
+	// Changed component levels replace the overrides.
+	opts.ComponentLevels = map[string]slog.Level{"service": slog.LevelWarn}
+	if err := Reconfigure(opts); err != nil {
+		t.Fatalf("Reconfigure() error = %v", err)
+	}
+	if got := ComponentLevels(); len(got) != 1 || got["service"] != "warn" {
+		t.Errorf("ComponentLevels() = %v, want only service=warn", got)
+	}
+}
+
+func TestReconfigure_Outputs(t *testing.T) {
+	t.Cleanup(func() {
+		level.Set(slog.LevelInfo)
+		SetComponentLevels(nil)
+	})
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.log")
+	second := filepath.Join(dir, "second.log")
+
+	opts := Options{Level: "info", Outputs: []string{"file:" + first}}
+	if err := Setup(opts); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	previous := Default()
+
+	// Only a level change keeps the outputs and the logger.
+	opts.Level = "warn"
+	if err := Reconfigure(opts); err != nil {
+		t.Fatalf("Reconfigure() error = %v", err)
+	}
+	if Default() != previous {
+		t.Error("Reconfigure() replaced the logger although no output setting changed")
+	}
+
+	// A new output replaces the logger, but loggers derived from the
+	// previous one can still write to its outputs.
+	opts.Outputs = []string{"file:" + second}
+	if err := Reconfigure(opts); err != nil {
+		t.Fatalf("Reconfigure() error = %v", err)
+	}
+	if Default() == previous {
+		t.Fatal("Reconfigure() kept the logger although the outputs changed")
+	}
+	previous.Warn("late record")
+	Warn("new record")
+	if err := Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	for path, want := range map[string]string{first: "late record", second: "new record"} {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) error = %v", path, err)
+		}
+		if !strings.Contains(string(content), want) {
+			t.Errorf("%s = %q, want %q", path, content, want)
+		}
+	}
+}
+
+func TestReconfigure_ClosesRetiredOutputs(t *testing.T) {
+	delay := SinkCloseDelay
+	SinkCloseDelay = time.Millisecond
+	t.Cleanup(func() { SinkCloseDelay = delay })
+	dir := t.TempDir()
+
+	if err := Setup(Options{Outputs: []string{"file:" + filepath.Join(dir, "first.log")}}); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	defer Close()
+	if err := Reconfigure(Options{Outputs: []string{"file:" + filepath.Join(dir, "second.log")}}); err != nil {
+		t.Fatalf("Reconfigure() error = %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stateMu.Lock()
+		n := len(retired)
+		stateMu.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("retired outputs were not closed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestReconfigure_ConcurrentLogging reconfigures the outputs while request
+// loggers write, as a config reload does while requests are served. Run
+// with -race; no record may be lost.
+func TestReconfigure_ConcurrentLogging(t *testing.T) {
+	t.Cleanup(func() {
+		level.Set(slog.LevelInfo)
+		SetComponentLevels(nil)
+	})
+	dir := t.TempDir()
+	paths := []string{filepath.Join(dir, "a.log"), filepath.Join(dir, "b.log")}
+
+	if err := Setup(Options{Level: "info", Outputs: []string{"file:" + paths[0]}}); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+
+	const writers, records = 8, 200
+	var wg sync.WaitGroup
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range records {
+				// Like a request: a logger derived from the global one.
+				ctx := WithContext(context.Background(), FromContext(context.Background()).With("writer", w))
+				Component(ctx, "handler").Info("request", "i", i)
+			}
+		}()
+	}
+
+	for i := range 20 {
+		opts := Options{Level: "info", JSON: i%2 == 0, Outputs: []string{"file:" + paths[i%2]}}
+		if err := Reconfigure(opts); err != nil {
+			t.Errorf("Reconfigure() error = %v", err)
+		}
+	}
+	wg.Wait()
+	if err := Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	total := 0
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) error = %v", path, err)
+		}
+		total += strings.Count(string(content), "request")
+	}
+	if total != writers*records {
+		t.Errorf("%d records written, want %d", total, writers*records)
+	}
+}
@@ -0,0 +1,225 @@
+package logger
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+)
+
+// RedactedValue replaces masked values in log output.
+const RedactedValue = "[REDACTED]"
+
+// headersGroup is the attribute group whose members are treated as HTTP headers.
+const headersGroup = "headers"
+
+var (
+	// queryKeys are attribute keys holding a raw URL query string.
+	queryKeys = map[string]bool{"query": true, "raw_query": true}
+	// urlKeys are attribute keys holding a URL whose query may need masking.
+	urlKeys = map[string]bool{"url": true, "uri": true, "request_uri": true, "referer": true}
+	// ipKeys are attribute keys holding a client IP address.
+	ipKeys = map[string]bool{"client_ip": true, "remote_addr": true, "ip": true}
+)
+
+// RedactOptions configures masking of sensitive values in log records.
+// Names are matched case-insensitively.
+type RedactOptions struct {
+	// Keys are attribute keys whose values are always masked.
+	Keys []string
+	// QueryParams are URL query parameters masked in query and URL attributes.
+	QueryParams []string
+	// Headers are HTTP header names masked in http.Header values and in
+	// attributes grouped under "headers".
+	Headers []string
+	// HashIPs replaces client IP attributes with a salted hash so requests
+	// from one client can still be correlated.
+	HashIPs bool
+	// IPHashSalt is mixed into IP hashes.
+	IPHashSalt string
+}
+
+// enabled reports whether any redaction is configured.
+func (o RedactOptions) enabled() bool {
+	return len(o.Keys) > 0 || len(o.QueryParams) > 0 || len(o.Headers) > 0 || o.HashIPs
+}
+
+// activeRedactor is the redactor installed by the most recent Setup call.
+var activeRedactor atomic.Pointer[redactor]
+
+// Redact applies the redaction configured by the most recent Setup call to
+// a single value as if it were logged under key. It lets output that
+// bypasses slog, such as access logs, honour the same rules.
+func Redact(key, value string) string {
+	r := activeRedactor.Load()
+	if r == nil {
+		return value
+	}
+	return r.attr(slog.String(key, value), false).Value.String()
+}
+
+// redactor applies RedactOptions to attributes.
+type redactor struct {
+	keys    map[string]bool
+	query   map[string]bool
+	headers map[string]bool
+	hashIPs bool
+	salt    string
+}
+
+// newRedactor builds a redactor with lowercased lookup sets.
+func newRedactor(opts RedactOptions) *redactor {
+	return &redactor{
+		keys:    lowerSet(opts.Keys),
+		query:   lowerSet(opts.QueryParams),
+		headers: lowerSet(opts.Headers),
+		hashIPs: opts.HashIPs,
+		salt:    opts.IPHashSalt,
+	}
+}
+
+// redactHandler masks sensitive attribute values before passing records on.
+type redactHandler struct {
+	next      slog.Handler
+	r         *redactor
+	inHeaders bool
+}
+
+// newRedactHandler wraps next with redaction according to opts.
+func newRedactHandler(next slog.Handler, opts RedactOptions) *redactHandler {
+	return &redactHandler{next: next, r: newRedactor(opts)}
+}
+
+// Enabled implements slog.Handler.
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.r.attr(a, h.inHeaders))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.r.attr(a, h.inHeaders)
+	}
+	return &redactHandler{next: h.next.WithAttrs(redacted), r: h.r, inHeaders: h.inHeaders}
+}
+
+// WithGroup implements slog.Handler.
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{
+		next:      h.next.WithGroup(name),
+		r:         h.r,
+		inHeaders: h.inHeaders || strings.EqualFold(name, headersGroup),
+	}
+}
+
+// attr returns a with any sensitive values masked. inHeaders reports
+// whether a is a member of a headers group.
+func (r *redactor) attr(a slog.Attr, inHeaders bool) slog.Attr {
+	a.Value = a.Value.Resolve()
+	key := strings.ToLower(a.Key)
+
+	if r.keys[key] || (inHeaders && r.headers[key]) {
+		return slog.String(a.Key, RedactedValue)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		members := a.Value.Group()
+		redacted := make([]slog.Attr, len(members))
+		for i, member := range members {
+			redacted[i] = r.attr(member, inHeaders || key == headersGroup)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindAny:
+		if header, ok := a.Value.Any().(http.Header); ok {
+			return r.header(a.Key, header)
+		}
+	case slog.KindString:
+		value := a.Value.String()
+		switch {
+		case queryKeys[key]:
+			return slog.String(a.Key, r.rawQuery(value))
+		case urlKeys[key]:
+			return slog.String(a.Key, r.url(value))
+		case ipKeys[key] && r.hashIPs && value != "":
+			return slog.String(a.Key, r.hashIP(value))
+		}
+	}
+
+	return a
+}
+
+// header renders an http.Header as a group, masking configured headers.
+func (r *redactor) header(key string, header http.Header) slog.Attr {
+	attrs := make([]slog.Attr, 0, len(header))
+	for name, values := range header {
+		if r.headers[strings.ToLower(name)] {
+			attrs = append(attrs, slog.String(name, RedactedValue))
+			continue
+		}
+		attrs = append(attrs, slog.String(name, strings.Join(values, ", ")))
+	}
+	return slog.Attr{Key: key, Value: slog.GroupValue(attrs...)}
+}
+
+// rawQuery masks configured parameters in a raw query string, preserving
+// parameter order and encoding of the rest.
+func (r *redactor) rawQuery(raw string) string {
+	if len(r.query) == 0 || raw == "" {
+		return raw
+	}
+
+	pairs := strings.Split(raw, "&")
+	for i, pair := range pairs {
+		name, _, hasValue := strings.Cut(pair, "=")
+		decoded, err := url.QueryUnescape(name)
+		if err != nil {
+			decoded = name
+		}
+		if hasValue && r.query[strings.ToLower(decoded)] {
+			pairs[i] = name + "=" + RedactedValue
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// url masks configured query parameters in a URL.
+func (r *redactor) url(raw string) string {
+	base, query, ok := strings.Cut(raw, "?")
+	if !ok {
+		return raw
+	}
+	return base + "?" + r.rawQuery(query)
+}
+
+// hashIP returns a short salted hash of ip.
+func (r *redactor) hashIP(ip string) string {
+	sum := sha256.Sum256([]byte(r.salt + ip))
+	return "ip-" + hex.EncodeToString(sum[:8])
+}
+
+// lowerSet builds a lookup set of lowercased, trimmed names.
+func lowerSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			set[strings.ToLower(name)] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// newRedactTestLogger returns a logger writing redacted text records to buf.
+func newRedactTestLogger(buf *bytes.Buffer, opts RedactOptions) *slog.Logger {
+	return slog.New(newRedactHandler(slog.NewTextHandler(buf, nil), opts))
+}
+
+func TestRedactHandler_Keys(t *testing.T) {
+	var buf bytes.Buffer
+	l := newRedactTestLogger(&buf, RedactOptions{Keys: []string{"password", "Token"}})
+
+	l.With("token", "abc").Info("login", "user", "alice", "PASSWORD", "hunter2",
+		slog.Group("nested", slog.String("password", "hunter3")))
+
+	out := buf.String()
+	for _, secret := range []string{"abc", "hunter2", "hunter3"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("output = %q, should not contain %q", out, secret)
+		}
+	}
+	if !strings.Contains(out, "user=alice") {
+		t.Errorf("output = %q, should keep non-sensitive values", out)
+	}
+}
+
+func TestRedactHandler_QueryParams(t *testing.T) {
+	var buf bytes.Buffer
+	l := newRedactTestLogger(&buf, RedactOptions{QueryParams: []string{"api_key", "token"}})
+
+	l.Info("request",
+		"query", "page=2&api_key=s3cret&TOKEN=t0k",
+		"referer", "https://example.com/cb?token=t1k&x=1",
+		"path", "/schools?api_key=untouched",
+	)
+
+	out := buf.String()
+	if !strings.Contains(out, "query=\"page=2&api_key=[REDACTED]&TOKEN=[REDACTED]\"") {
+		t.Errorf("output = %q, want query parameters masked", out)
+	}
+	if !strings.Contains(out, "referer=\"https://example.com/cb?token=[REDACTED]&x=1\"") {
+		t.Errorf("output = %q, want URL query masked", out)
+	}
+	if !strings.Contains(out, `path="/schools?api_key=untouched"`) {
+		t.Errorf("output = %q, non-URL attributes should be left alone", out)
+	}
+}
+
+func TestRedactHandler_Headers(t *testing.T) {
+	var buf bytes.Buffer
+	l := newRedactTestLogger(&buf, RedactOptions{Headers: []string{"authorization", "X-Api-Key"}})
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer secret-token")
+	header.Set("Accept", "application/json")
+
+	l.Info("request", "request_headers", header)
+	l.WithGroup("headers").Info("grouped", "X-Api-Key", "k3y", "User-Agent", "curl")
+
+	out := buf.String()
+	if strings.Contains(out, "secret-token") || strings.Contains(out, "k3y") {
+		t.Errorf("output = %q, should not contain header secrets", out)
+	}
+	if !strings.Contains(out, "application/json") || !strings.Contains(out, "curl") {
+		t.Errorf("output = %q, should keep other headers", out)
+	}
+}
+
+func TestRedactHandler_HashIPs(t *testing.T) {
+	var buf bytes.Buffer
+	l := newRedactTestLogger(&buf, RedactOptions{HashIPs: true, IPHashSalt: "pepper"})
+
+	l.Info("first", "client_ip", "203.0.113.7")
+	l.Info("second", "client_ip", "203.0.113.7")
+
+	out := buf.String()
+	if strings.Contains(out, "203.0.113.7") {
+		t.Errorf("output = %q, should not contain the raw IP", out)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	hash := func(line string) string {
+		_, after, _ := strings.Cut(line, "client_ip=")
+		return after
+	}
+	if len(lines) != 2 || hash(lines[0]) == "" || hash(lines[0]) != hash(lines[1]) {
+		t.Errorf("output = %q, same IP should hash to the same value", out)
+	}
+}
+
+func TestSetup_Redact(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Setup(Options{Redact: RedactOptions{Keys: []string{"secret"}}}); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	defer Close()
+
+	// Redaction wraps the configured outputs; swap in a buffer to inspect.
+	h, ok := Default().Handler().(*levelHandler).next.(*redactHandler)
+	if !ok {
+		t.Fatalf("Setup() handler chain = %T, want redaction", Default().Handler())
+	}
+	h.next = slog.NewTextHandler(&buf, nil)
+
+	Info("configured", "secret", "value")
+	if strings.Contains(buf.String(), "value") {
+		t.Errorf("output = %q, global logger should redact", buf.String())
+	}
+
+	if got := Redact("secret", "value"); got != RedactedValue {
+		t.Errorf("Redact() = %q, want %q", got, RedactedValue)
+	}
+	if got := Redact("other", "value"); got != "value" {
+		t.Errorf("Redact() = %q, want value unchanged", got)
+	}
+}
@@ -0,0 +1,250 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileOptions configures rotation and retention for file sinks.
+// A zero value disables the corresponding behaviour.
+type FileOptions struct {
+	// MaxSize is the size in bytes after which the file is rotated.
+	MaxSize int64
+	// RotateInterval is the age after which the file is rotated.
+	RotateInterval time.Duration
+	// MaxBackups is the number of rotated files to keep.
+	MaxBackups int
+	// MaxAge is how long rotated files are kept.
+	MaxAge time.Duration
+}
+
+// OpenSink opens the log output described by spec. Supported specs are:
+//
+//	stdout             standard output
+//	stderr             standard error
+//	file:<path>        a file, rotated according to opts
+//	syslog[:<socket>]  the local syslog daemon, via /dev/log by default
+//
+// Closing a stdout or stderr sink does not close the underlying stream.
+func OpenSink(spec string, opts FileOptions) (io.WriteCloser, error) {
+	kind, arg, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "stdout":
+		return stdSink{os.Stdout}, nil
+	case "stderr":
+		return stdSink{os.Stderr}, nil
+	case "file":
+		if arg == "" {
+			return nil, fmt.Errorf("file sink requires a path")
+		}
+		f, err := OpenRotatingFile(arg, opts)
+		if err != nil {
+			return nil, err
+		}
+		return f, nil
+	case "syslog":
+		w, err := dialSyslog(arg)
+		if err != nil {
+			return nil, err
+		}
+		return w, nil
+	default:
+		return nil, fmt.Errorf("unknown log sink: %s", spec)
+	}
+}
+
+// ValidateSink reports whether spec is a well-formed sink specification
+// without opening it.
+func ValidateSink(spec string) error {
+	kind, arg, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "stdout", "stderr", "syslog":
+		return nil
+	case "file":
+		if arg == "" {
+			return fmt.Errorf("file sink requires a path")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown log sink: %s (must be stdout, stderr, file:<path>, or syslog[:<socket>])", spec)
+	}
+}
+
+// stdSink wraps a standard stream so that closing it is a no-op.
+type stdSink struct {
+	*os.File
+}
+
+// Close implements io.Closer without closing the underlying stream.
+func (stdSink) Close() error {
+	return nil
+}
+
+// backupTimeFormat is the timestamp suffix appended to rotated files.
+const backupTimeFormat = "20060102T150405.000"
+
+// RotatingFile is an io.WriteCloser that writes to a file and rotates it
+// by size and age, pruning old backups. It is safe for concurrent use.
+type RotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	opts     FileOptions
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	now      func() time.Time
+}
+
+// OpenRotatingFile opens or creates the file at path for appending.
+func OpenRotatingFile(path string, opts FileOptions) (*RotatingFile, error) {
+	r := &RotatingFile{
+		path: path,
+		opts: opts,
+		now:  time.Now,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Write writes p to the file, rotating first if the write would exceed
+// the size limit or the file has outlived the rotation interval.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if r.shouldRotate(int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close closes the underlying file.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// Rotate forces a rotation of the file.
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rotate()
+}
+
+// shouldRotate reports whether the file must be rotated before writing n bytes.
+func (r *RotatingFile) shouldRotate(n int64) bool {
+	if r.size == 0 {
+		return false
+	}
+	if r.opts.MaxSize > 0 && r.size+n > r.opts.MaxSize {
+		return true
+	}
+	if r.opts.RotateInterval > 0 && r.now().Sub(r.openedAt) >= r.opts.RotateInterval {
+		return true
+	}
+	return false
+}
+
+// open opens the log file for appending, creating its directory if needed.
+func (r *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("could not create log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("could not open log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("could not stat log file: %w", err)
+	}
+
+	r.file = file
+	r.size = info.Size()
+	r.openedAt = r.now()
+	return nil
+}
+
+// rotate renames the current file to a timestamped backup, reopens the
+// log file and prunes backups according to the retention settings.
+func (r *RotatingFile) rotate() error {
+	if r.file != nil {
+		if err := r.file.Close(); err != nil {
+			return fmt.Errorf("could not close log file: %w", err)
+		}
+		r.file = nil
+	}
+
+	backup := r.path + "." + r.now().Format(backupTimeFormat)
+	if err := os.Rename(r.path, backup); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("could not rotate log file: %w", err)
+	}
+
+	if err := r.open(); err != nil {
+		return err
+	}
+
+	return r.prune()
+}
+
+// prune removes backups beyond MaxBackups or older than MaxAge.
+func (r *RotatingFile) prune() error {
+	if r.opts.MaxBackups <= 0 && r.opts.MaxAge <= 0 {
+		return nil
+	}
+
+	backups, err := filepath.Glob(r.path + ".*")
+	if err != nil {
+		return fmt.Errorf("could not list log backups: %w", err)
+	}
+	// Timestamp suffixes sort chronologically; newest first.
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	now := r.now()
+	for i, backup := range backups {
+		expired := false
+		if r.opts.MaxBackups > 0 && i >= r.opts.MaxBackups {
+			expired = true
+		}
+		if r.opts.MaxAge > 0 {
+			stamp := strings.TrimPrefix(backup, r.path+".")
+			if t, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local); err == nil && now.Sub(t) > r.opts.MaxAge {
+				expired = true
+			}
+		}
+		if expired {
+			if err := os.Remove(backup); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("could not remove log backup: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenSink(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{name: "stdout", spec: "stdout", wantErr: false},
+		{name: "stderr", spec: "stderr", wantErr: false},
+		{name: "file", spec: "file:" + filepath.Join(dir, "app.log"), wantErr: false},
+		{name: "file without path", spec: "file:", wantErr: true},
+		{name: "unknown", spec: "kafka:topic", wantErr: true},
+		{name: "missing syslog socket", spec: "syslog:" + filepath.Join(dir, "missing.sock"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := OpenSink(tt.spec, FileOptions{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OpenSink(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if w != nil {
+				if err := w.Close(); err != nil {
+					t.Errorf("Close() error = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSink(t *testing.T) {
+	for _, spec := range []string{"stdout", "stderr", "file:/tmp/app.log", "syslog", "syslog:/dev/log"} {
+		if err := ValidateSink(spec); err != nil {
+			t.Errorf("ValidateSink(%q) error = %v", spec, err)
+		}
+	}
+	for _, spec := range []string{"", "file", "file:", "nowhere"} {
+		if err := ValidateSink(spec); err == nil {
+			t.Errorf("ValidateSink(%q) expected error", spec)
+		}
+	}
+}
+
+func TestRotatingFile_RotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local)
+
+	r, err := OpenRotatingFile(path, FileOptions{MaxSize: 10, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error = %v", err)
+	}
+	defer r.Close()
+	r.now = func() time.Time { return clock }
+
+	for i := 0; i < 4; i++ {
+		clock = clock.Add(time.Second)
+		if _, err := r.Write([]byte("0123456789")); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 2 {
+		t.Errorf("backups = %v, want 2 retained", backups)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(content) != "0123456789" {
+		t.Errorf("current file = %q, want only the last write", content)
+	}
+}
+
+func TestRotatingFile_RotatesByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local)
+
+	r, err := OpenRotatingFile(path, FileOptions{RotateInterval: time.Hour, MaxAge: 30 * time.Minute})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error = %v", err)
+	}
+	defer r.Close()
+	r.now = func() time.Time { return clock }
+	r.openedAt = clock
+
+	write := func() {
+		if _, err := r.Write([]byte("line\n")); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	write()
+	clock = clock.Add(30 * time.Minute)
+	write()
+	if backups, _ := filepath.Glob(path + ".*"); len(backups) != 0 {
+		t.Fatalf("backups = %v, want none before the interval elapses", backups)
+	}
+
+	clock = clock.Add(time.Hour)
+	write()
+	clock = clock.Add(time.Hour)
+	write()
+
+	// Backups are stamped with their rotation time; the first one is now
+	// older than MaxAge and must have been pruned.
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 1 {
+		t.Errorf("backups = %v, want 1 within retention", backups)
+	}
+	for _, b := range backups {
+		if !strings.HasPrefix(b, path+".") {
+			t.Errorf("unexpected backup name %q", b)
+		}
+	}
+}
+
+func TestRotatingFile_WriteAfterClose(t *testing.T) {
+	r, err := OpenRotatingFile(filepath.Join(t.TempDir(), "app.log"), FileOptions{})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error = %v", err)
+	}
+	r.Close()
+
+	if _, err := r.Write([]byte("late")); err == nil {
+		t.Error("Write() after Close() expected error")
+	}
+}
@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// defaultSyslogSockets are the local syslog sockets tried in order when
+// no socket path is given.
+var defaultSyslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// Syslog severities (RFC 5424).
+const (
+	severityError   = 3
+	severityWarning = 4
+	severityInfo    = 6
+	severityDebug   = 7
+
+	// facilityUser is the syslog facility for user-level messages.
+	facilityUser = 1
+)
+
+// syslogWriter sends each write as one datagram to a local syslog socket.
+// The severity used for a write is set by syslogHandler while it holds mu.
+type syslogWriter struct {
+	mu       sync.Mutex
+	conn     net.Conn
+	tag      string
+	severity int
+}
+
+// dialSyslog connects to the syslog socket at path, or to the first
+// available default socket if path is empty.
+func dialSyslog(path string) (*syslogWriter, error) {
+	candidates := defaultSyslogSockets
+	if path != "" {
+		candidates = []string{path}
+	}
+
+	var lastErr error
+	for _, candidate := range candidates {
+		conn, err := net.Dial("unixgram", candidate)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return &syslogWriter{
+			conn:     conn,
+			tag:      filepath.Base(os.Args[0]),
+			severity: severityInfo,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("could not connect to syslog: %w", lastErr)
+}
+
+// Write sends p as a single syslog message at informational severity.
+func (w *syslogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.write(p)
+}
+
+// write formats p as an RFC 3164 message and sends it. Callers that need
+// a specific severity must hold mu and set severity first.
+func (w *syslogWriter) write(p []byte) (int, error) {
+	// Trailing newlines are framing for stream outputs, not message content.
+	for len(p) > 0 && p[len(p)-1] == '\n' {
+		p = p[:len(p)-1]
+	}
+
+	msg := fmt.Sprintf("<%d>%s %s[%d]: %s",
+		facilityUser*8+w.severity,
+		time.Now().Format(time.Stamp),
+		w.tag,
+		os.Getpid(),
+		p,
+	)
+	if _, err := w.conn.Write([]byte(msg)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the syslog connection.
+func (w *syslogWriter) Close() error {
+	return w.conn.Close()
+}
+
+// syslogHandler maps record levels to syslog severities before delegating
+// formatting to next, whose output is the shared syslogWriter.
+type syslogHandler struct {
+	next slog.Handler
+	w    *syslogWriter
+}
+
+// newSyslogHandler creates a handler formatting records as text or JSON
+// and sending them to w with a severity matching each record's level.
+func newSyslogHandler(w *syslogWriter, jsonOutput bool, opts *slog.HandlerOptions) *syslogHandler {
+	// The formatter writes through the unlocked path because Handle
+	// already holds the writer's lock.
+	return &syslogHandler{
+		next: newFormatHandler(writerFunc(w.write), jsonOutput, opts),
+		w:    w,
+	}
+}
+
+// writerFunc adapts a function to io.Writer.
+type writerFunc func(p []byte) (int, error)
+
+// Write implements io.Writer.
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+// Enabled implements slog.Handler.
+func (h *syslogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *syslogHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.w.mu.Lock()
+	defer h.w.mu.Unlock()
+
+	previous := h.w.severity
+	h.w.severity = syslogSeverity(r.Level)
+	defer func() { h.w.severity = previous }()
+
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *syslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &syslogHandler{next: h.next.WithAttrs(attrs), w: h.w}
+}
+
+// WithGroup implements slog.Handler.
+func (h *syslogHandler) WithGroup(name string) slog.Handler {
+	return &syslogHandler{next: h.next.WithGroup(name), w: h.w}
+}
+
+// syslogSeverity maps a slog level to a syslog severity.
+func syslogSeverity(level slog.Level) int {
+	switch {
+	case level >= slog.LevelError:
+		return severityError
+	case level >= slog.LevelWarn:
+		return severityWarning
+	case level >= slog.LevelInfo:
+		return severityInfo
+	default:
+		return severityDebug
+	}
+}
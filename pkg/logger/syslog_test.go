@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"log/slog"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listenSyslog starts a local datagram socket standing in for the syslog daemon.
+func listenSyslog(t *testing.T) (string, *net.UnixConn) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "log.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unixgram sockets unavailable: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return path, conn
+}
+
+// readDatagram reads one message from conn.
+func readDatagram(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	return string(buf[:n])
+}
+
+func TestSyslogSink(t *testing.T) {
+	path, conn := listenSyslog(t)
+
+	w, err := OpenSink("syslog:"+path, FileOptions{})
+	if err != nil {
+		t.Fatalf("OpenSink() error = %v", err)
+	}
+	defer w.Close()
+
+	sw := w.(*syslogWriter)
+	l := slog.New(newSyslogHandler(sw, false, nil))
+
+	l.Warn("disk almost full", "free_mb", 12)
+	msg := readDatagram(t, conn)
+
+	// facility user (1) * 8 + warning (4) = 12
+	if !strings.HasPrefix(msg, "<12>") {
+		t.Errorf("message = %q, want priority <12>", msg)
+	}
+	if !strings.Contains(msg, `msg="disk almost full" free_mb=12`) {
+		t.Errorf("message = %q, want formatted record", msg)
+	}
+	if strings.HasSuffix(msg, "\n") {
+		t.Errorf("message = %q, should not end with a newline", msg)
+	}
+
+	// Plain writes use informational severity: 1 * 8 + 6 = 14
+	if _, err := w.Write([]byte("plain line\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if msg := readDatagram(t, conn); !strings.HasPrefix(msg, "<14>") {
+		t.Errorf("message = %q, want priority <14>", msg)
+	}
+}